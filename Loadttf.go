@@ -1,25 +1,45 @@
 package main
 
 import (
-	"io/ioutil"
+	"io/fs"
 	"log"
 
+	"github.com/baag_chal_gl/assets"
 	"github.com/golang/freetype/truetype"
 )
 
 
 var mainFont *truetype.Font
 
+// defaultFontPath is the built-in font used when a theme's font is missing
+const defaultFontPath = assets.DefaultFont
+
+// loadedFonts caches parsed fonts by path so theme switches reuse them
+var loadedFonts = map[string]*truetype.Font{}
+
 func LoadFont(ttfPath string) error {
-	data, err := ioutil.ReadFile(ttfPath)
+	font, err := parseFont(ttfPath)
 	if err != nil {
 			return err
 	}
+	mainFont = font
+	return nil
+}
+
+// parseFont reads a font from the asset filesystem, once per path.
+func parseFont(ttfPath string) (*truetype.Font, error) {
+	if font, ok := loadedFonts[ttfPath]; ok {
+		return font, nil
+	}
+	data, err := fs.ReadFile(assetFS, ttfPath)
+	if err != nil {
+			return nil, err
+	}
 	font, err := truetype.Parse(data)
 	if err != nil {
-			return err
+			return nil, err
 	}
-	mainFont = font
+	loadedFonts[ttfPath] = font
 	log.Println("[Font] Loaded font from:", ttfPath)
-	return nil
+	return font, nil
 }
@@ -0,0 +1,258 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/baag_chal_gl/rules"
+	"github.com/go-gl/glfw/v3.3/glfw"
+)
+
+// Accessibility: a plain-text description of the game for screen readers,
+// spoken-style announcements on stdout, highlight palettes that do not rely
+// on telling red from green, and a UI scale for text and outlines.
+
+var (
+	announceFlag = flag.Bool("announce", false, "print a line for every move and cursor step, for screen readers")
+	paletteFlag  = flag.String("palette", "theme", "highlight colours: theme, colorblind or contrast")
+	uiScaleFlag  = flag.Float64("uiscale", 1, "scale for text and outlines, 0.75 to 2")
+)
+
+// palette is a set of highlight colours. The "theme" palette takes them
+// from the current theme.
+type palette struct {
+	ID, Name  string
+	Highlight themeColor
+	Cursor    themeColor
+	LastMove  themeColor
+	Threat    themeColor
+}
+
+// The colour-blind palette uses the Okabe-Ito colours.
+var palettes = []*palette{
+	{ID: "theme", Name: "Theme colours"},
+	{
+		ID: "colorblind", Name: "Colour-blind safe",
+		Highlight: themeColor{0.90, 0.62, 0, 0.7},
+		Cursor:    themeColor{0.34, 0.71, 0.91, 1},
+		LastMove:  themeColor{0, 0.62, 0.45, 0.6},
+		Threat:    themeColor{0.84, 0.37, 0, 0.7},
+	},
+	{
+		ID: "contrast", Name: "High contrast",
+		Highlight: themeColor{1, 1, 0, 0.8},
+		Cursor:    themeColor{0, 1, 1, 1},
+		LastMove:  themeColor{1, 1, 1, 0.7},
+		Threat:    themeColor{1, 0, 1, 0.8},
+	},
+}
+
+var currentPalette = palettes[0]
+
+// findPalette returns the palette with the given ID, or nil.
+func findPalette(id string) *palette {
+	for _, p := range palettes {
+		if p.ID == id {
+			return p
+		}
+	}
+	return nil
+}
+
+// highlightColors returns the highlight colours in use.
+func highlightColors() palette {
+	if currentPalette.ID != "theme" {
+		return *currentPalette
+	}
+	return palette{
+		ID:        "theme",
+		Highlight: theme.Highlight,
+		Cursor:    themeColor{theme.Highlight[0], theme.Highlight[1], theme.Highlight[2], 1},
+		LastMove:  theme.LastMove,
+		Threat:    theme.Threat,
+	}
+}
+
+// cyclePalette switches to the next palette.
+func cyclePalette() {
+	for i, p := range palettes {
+		if p == currentPalette {
+			currentPalette = palettes[(i+1)%len(palettes)]
+			break
+		}
+	}
+	log.Printf("Highlight palette: %s", currentPalette.Name)
+	announce("Highlight palette: " + currentPalette.Name + ".")
+}
+
+var uiScaleValue = 1.0
+
+// uiScale is the user's scale for text and outlines.
+func uiScale() float32 {
+	return float32(uiScaleValue)
+}
+
+// setUIScale changes the UI scale, kept between 0.75 and 2.
+func setUIScale(s float64) {
+	uiScaleValue = min(max(s, 0.75), 2)
+}
+
+// cycleUIScale steps the UI scale up a quarter, back to the smallest
+// after the largest.
+func cycleUIScale() {
+	if uiScaleValue >= 2 {
+		setUIScale(0.75)
+		return
+	}
+	setUIScale(uiScaleValue + 0.25)
+}
+
+// initAccessibility applies the accessibility flags.
+func initAccessibility() {
+	if p := findPalette(*paletteFlag); p != nil {
+		currentPalette = p
+	} else {
+		log.Printf("Unknown palette %q, using theme colours", *paletteFlag)
+	}
+	setUIScale(*uiScaleFlag)
+}
+
+// announce prints text for screen readers when -announce is set.
+func announce(text string) {
+	if *announceFlag {
+		fmt.Println(text)
+	}
+}
+
+// announceMove describes a move just played on top of before.
+func announceMove(before rules.Position, m rules.Move) {
+	if !*announceFlag {
+		return
+	}
+	after := history.Position()
+	announce(describeMove(&before, m) + ". " + sideName(after.Turn) + " to move.")
+}
+
+// sideName is "Goats" or "Tigers".
+func sideName(side int) string {
+	if side == rules.Tiger {
+		return "Tigers"
+	}
+	return "Goats"
+}
+
+// pieceName is "goat", "tiger" or "empty".
+func pieceName(piece int) string {
+	switch piece {
+	case rules.Goat:
+		return "goat"
+	case rules.Tiger:
+		return "tiger"
+	}
+	return "empty"
+}
+
+// describePoint names a point and what is on it, e.g. "c3, goat".
+func describePoint(p *rules.Position, pt [2]int) string {
+	return rules.PointString(pt) + ", " + pieceName(p.At(pt))
+}
+
+// describeMove puts m, played from p, into words.
+func describeMove(p *rules.Position, m rules.Move) string {
+	switch {
+	case m.IsPlacement():
+		return "Goat placed on " + rules.PointString(m.To)
+	case m.IsJump():
+		return fmt.Sprintf("Tiger jumps from %s to %s, capturing the goat on %s",
+			rules.PointString(m.From), rules.PointString(m.To), rules.PointString(m.Captured()))
+	}
+	name := pieceName(p.At(m.From))
+	return fmt.Sprintf("%s moves from %s to %s",
+		strings.ToUpper(name[:1])+name[1:], rules.PointString(m.From), rules.PointString(m.To))
+}
+
+// pointList lists the points holding piece, e.g. "a1, e1".
+func pointList(p *rules.Position, piece int) string {
+	var names []string
+	for x := 0; x < rules.Size; x++ {
+		for y := 0; y < rules.Size; y++ {
+			if p.Board[x][y] == piece {
+				names = append(names, rules.PointString([2]int{x, y}))
+			}
+		}
+	}
+	if len(names) == 0 {
+		return "none"
+	}
+	return strings.Join(names, ", ")
+}
+
+// pointNames lists points in notation.
+func pointNames(points [][2]int) string {
+	names := make([]string, len(points))
+	for i, pt := range points {
+		names[i] = rules.PointString(pt)
+	}
+	return strings.Join(names, ", ")
+}
+
+// accessibilityText describes the shown position in plain text: whose turn
+// it is, the last move, the board, threats, and every legal move.
+func accessibilityText() string {
+	p := shownPosition()
+	ply := shownPly()
+	var b strings.Builder
+
+	if viewing() {
+		fmt.Fprintf(&b, "Viewing move %d of %d.\n", ply, history.Ply())
+	}
+	if r := p.Result(); r != rules.Ongoing {
+		fmt.Fprintf(&b, "Game over: %s.\n", r)
+	} else {
+		fmt.Fprintf(&b, "%s to move.\n", sideName(p.Turn))
+	}
+	fmt.Fprintf(&b, "%s.\n", p.Summary())
+	if ply > 0 {
+		before := history.PositionAt(ply - 1)
+		fmt.Fprintf(&b, "Last move: %s.\n", describeMove(&before, history.Moves[ply-1]))
+	}
+
+	b.WriteString("Board, G goat, T tiger, rank 5 at the top:\n")
+	for y := rules.Size - 1; y >= 0; y-- {
+		fmt.Fprintf(&b, "%d", y+1)
+		for x := 0; x < rules.Size; x++ {
+			b.WriteString(" " + string(".GT"[p.Board[x][y]]))
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("  a b c d e\n")
+	fmt.Fprintf(&b, "Tigers: %s.\n", pointList(&p, rules.Tiger))
+	fmt.Fprintf(&b, "Goats: %s.\n", pointList(&p, rules.Goat))
+	if goats := p.ThreatenedGoats(); len(goats) > 0 {
+		fmt.Fprintf(&b, "Goats that can be captured: %s.\n", pointNames(goats))
+	}
+	if tigers := p.TrappedTigers(); len(tigers) > 0 {
+		fmt.Fprintf(&b, "Trapped tigers: %s.\n", pointNames(tigers))
+	}
+
+	moves := p.LegalMoves()
+	names := make([]string, len(moves))
+	for i, m := range moves {
+		names[i] = m.String()
+	}
+	fmt.Fprintf(&b, "Legal moves (%d): %s.\n", len(moves), strings.Join(names, ", "))
+	if kb.active {
+		fmt.Fprintf(&b, "Cursor: %s.\n", describePoint(&p, kb.cursor))
+	}
+	return b.String()
+}
+
+// dumpAccessibility prints the description and copies it to the clipboard
+// so a screen reader can read it from either place.
+func dumpAccessibility(w *glfw.Window) {
+	text := accessibilityText()
+	fmt.Print(text)
+	w.SetClipboardString(text)
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"time"
+
+	"github.com/baag_chal_gl/engine"
+	"github.com/baag_chal_gl/rules"
+)
+
+// ai is the computer player. It searches on a goroutine so the window keeps
+// drawing; the chosen move is picked up by updateAI on the main thread and
+// played through submitMove like any other move.
+var ai = struct {
+	// side is rules.Goat or rules.Tiger, or 0 when both sides are human
+	side     int
+	level    string
+	moveTime time.Duration
+
+	eng      engine.Engine
+	cancel   context.CancelFunc
+	thinking bool
+	// gen invalidates searches started before an undo or new game
+	gen     int
+	results chan aiResult
+}{
+	level:    "medium",
+	moveTime: time.Second,
+	results:  make(chan aiResult, 1),
+}
+
+var (
+	aiFlag       = flag.String("ai", "none", "side the computer plays: none, goat or tiger")
+	levelFlag    = flag.String("level", "medium", "computer strength: random, easy, medium or hard")
+	moveTimeFlag = flag.Duration("movetime", time.Second, "longest time the computer thinks about a move")
+)
+
+// aiLevels are the engine presets offered in the settings.
+var aiLevels = []string{"random", "easy", "medium", "hard"}
+
+// aiMoveTimes are the thinking times offered in the settings.
+var aiMoveTimes = []time.Duration{250 * time.Millisecond, 500 * time.Millisecond, time.Second, 2 * time.Second, 5 * time.Second}
+
+// aiSides are the -ai flag values, indexed by side.
+var aiSides = []string{rules.Empty: "none", rules.Goat: "goat", rules.Tiger: "tiger"}
+
+// initAI applies the -ai, -level and -movetime flags.
+func initAI() {
+	side := 0
+	for i, name := range aiSides {
+		if name == *aiFlag {
+			side = i
+		}
+	}
+	ai.moveTime = *moveTimeFlag
+	if err := setAI(side, *levelFlag); err != nil {
+		log.Printf("[AI] %v", err)
+	}
+}
+
+// cycleAISide lets the computer play nobody, the goats, then the tigers.
+func cycleAISide() {
+	if err := setAI((ai.side+1)%len(aiSides), ai.level); err != nil {
+		log.Printf("[AI] %v", err)
+	}
+}
+
+// cycleAILevel switches the computer to the next strength.
+func cycleAILevel() {
+	next := aiLevels[0]
+	for i, l := range aiLevels {
+		if l == ai.level && i+1 < len(aiLevels) {
+			next = aiLevels[i+1]
+		}
+	}
+	if err := setAI(ai.side, next); err != nil {
+		log.Printf("[AI] %v", err)
+	}
+}
+
+// cycleMoveTime switches the computer to the next of aiMoveTimes. It
+// applies from the next move.
+func cycleMoveTime() {
+	next := aiMoveTimes[0]
+	for i, d := range aiMoveTimes {
+		if d == ai.moveTime && i+1 < len(aiMoveTimes) {
+			next = aiMoveTimes[i+1]
+		}
+	}
+	ai.moveTime = next
+}
+
+type aiResult struct {
+	gen  int
+	move rules.Move
+	err  error
+}
+
+// setAI lets the engine at the given level play side, or nobody for 0.
+func setAI(side int, level string) error {
+	stopAI()
+	if ai.eng != nil && level != ai.level {
+		ai.eng.Close()
+		ai.eng = nil
+	}
+	if side != 0 && ai.eng == nil {
+		e, err := engine.Open(level)
+		if err != nil {
+			return err
+		}
+		ai.eng = e
+	}
+	ai.side, ai.level = side, level
+	log.Printf("[AI] %s plays side %d", level, side)
+	return nil
+}
+
+// aiToMove reports whether the engine is to move in p.
+func aiToMove(p rules.Position) bool {
+	return ai.side != 0 && p.Turn == ai.side
+}
+
+// updateAI starts a search when it is the engine's turn and plays the
+// result once it arrives. Call it once per frame.
+func updateAI() {
+	select {
+	case r := <-ai.results:
+		ai.thinking = false
+		ai.cancel = nil
+		if r.gen != ai.gen {
+			break
+		}
+		if r.err != nil {
+			log.Printf("[AI] %v", r.err)
+			break
+		}
+		submitMove(r.move)
+	default:
+	}
+
+	if ai.thinking || gameOver || animating() || viewing() || !aiToMove(history.Position()) || ai.eng == nil {
+		return
+	}
+	startAI()
+}
+
+// startAI searches the current position on a copy of the game.
+func startAI() {
+	g := rules.NewGame(history.Start)
+	for _, m := range history.Moves {
+		g.Play(m)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), ai.moveTime)
+	ai.cancel = cancel
+	ai.thinking = true
+	gen, e := ai.gen, ai.eng
+	go func() {
+		defer cancel()
+		m, err := e.BestMove(ctx, g)
+		ai.results <- aiResult{gen, m, err}
+	}()
+}
+
+// aiMoveNow cuts the current search short so the engine plays its best
+// move so far.
+func aiMoveNow() {
+	if ai.cancel != nil {
+		ai.cancel()
+	}
+}
+
+// stopAI abandons any search in progress; its result will be ignored.
+func stopAI() {
+	ai.gen++
+	if ai.cancel != nil {
+		ai.cancel()
+	}
+}
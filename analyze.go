@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/baag_chal_gl/engine"
+	"github.com/baag_chal_gl/rules"
+)
+
+// The analyze command replays a game record with the engine, noting after
+// each move where the engine would have played something else.
+
+func runAnalyze(args []string) int {
+	fs := newFlagSet("analyze", "record-file|-", "level", "movetime")
+	out := fs.String("o", "-", "output file, or - for standard output")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+	loadSettings(fs)
+
+	if err := analyzeFile(fs.Arg(0), *out, analysisSpec(*levelFlag), *moveTimeFlag); err != nil {
+		fmt.Fprintln(os.Stderr, "analyze:", err)
+		return 1
+	}
+	return 0
+}
+
+// analysisSpec turns off the randomness of a built-in level, so that the
+// engine's choice is its best.
+func analysisSpec(level string) string {
+	if strings.HasPrefix(level, "cmd:") {
+		return level
+	}
+	return level + ",noise=0"
+}
+
+func analyzeFile(in, out, spec string, moveTime time.Duration) error {
+	data, err := readInput(in)
+	if err != nil {
+		return err
+	}
+	rec, err := rules.ParseRecord(string(data))
+	if err != nil {
+		return err
+	}
+	eng, err := engine.Open(spec)
+	if err != nil {
+		return err
+	}
+	defer eng.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	differ, err := analyzeRecord(ctx, eng, rec, moveTime)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "%d of %d moves differ from the engine (%s)\n", differ, rec.Game.Ply(), spec)
+
+	if out == "-" {
+		_, err = rec.WriteTo(os.Stdout)
+		return err
+	}
+	f, err := os.Create(out)
+	if err != nil {
+		return err
+	}
+	if _, err := rec.WriteTo(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// analyzeRecord asks eng for its move before each move of rec and comments
+// the moves it would not have played. It returns how many there were.
+func analyzeRecord(ctx context.Context, eng engine.Engine, rec *rules.Record, moveTime time.Duration) (int, error) {
+	g := rules.NewGame(rec.Game.Start)
+	differ := 0
+	for i, m := range rec.Game.Moves {
+		moveCtx, cancel := ctx, context.CancelFunc(func() {})
+		if moveTime > 0 {
+			moveCtx, cancel = context.WithTimeout(ctx, moveTime)
+		}
+		best, err := eng.BestMove(moveCtx, g)
+		cancel()
+		if err != nil {
+			return differ, fmt.Errorf("move %d: %w", i+1, err)
+		}
+		if ctx.Err() != nil {
+			return differ, ctx.Err()
+		}
+		if best != m {
+			differ++
+			addComment(rec, i+1, "engine prefers "+best.String())
+		}
+		if err := g.Play(m); err != nil {
+			return differ, fmt.Errorf("move %d: %w", i+1, err)
+		}
+	}
+	return differ, nil
+}
+
+// addComment adds text to the comment after ply, keeping what is there.
+func addComment(rec *rules.Record, ply int, text string) {
+	if c := rec.Comments[ply]; c != "" {
+		text = c + "; " + text
+	}
+	rec.Comments[ply] = text
+}
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"flag"
+	"math"
+
+	"github.com/baag_chal_gl/rules"
+	"github.com/go-gl/glfw/v3.3/glfw"
+)
+
+var animSpeed = flag.Float64("animspeed", 1, "animation speed multiplier, 0 disables animations")
+
+// Base durations in seconds at animspeed 1.
+const (
+	stepDuration    = 0.18
+	jumpDuration    = 0.38
+	placeDuration   = 0.22
+	snapDuration    = 0.25
+	captureDuration = 0.38
+
+	// jumpArcHeight is how high a capturing tiger is lifted, in NDC
+	jumpArcHeight = 0.18
+)
+
+// tween animates one piece sprite. Animations are driven by wall-clock time
+// so they run at the same speed at any frame rate.
+type tween struct {
+	piece    int
+	start    float64
+	duration float64
+
+	from, to [2]float32
+	// arc lifts the sprite along a parabola peaking mid-way
+	arc float32
+	// scale and alpha are interpolated from the first to the second value
+	scale, alpha [2]float32
+	// tint starts as this colour and fades to white
+	tint [4]float32
+	ease func(t float64) float64
+
+	// hide is the board point whose static sprite the tween replaces
+	hide [2]int
+}
+
+var (
+	tweens []*tween
+	// afterAnimations runs once all tweens have finished
+	afterAnimations []func()
+)
+
+var white = [4]float32{1, 1, 1, 1}
+
+func newTween(piece int, duration float64, from, to [2]float32) *tween {
+	return &tween{
+		piece:    piece,
+		start:    glfw.GetTime(),
+		duration: duration,
+		from:     from,
+		to:       to,
+		scale:    [2]float32{1, 1},
+		alpha:    [2]float32{1, 1},
+		tint:     white,
+		ease:     easeOutCubic,
+		hide:     rules.NoPoint,
+	}
+}
+
+// addTween queues t unless animations are switched off.
+func addTween(t *tween) {
+	if *animSpeed <= 0 {
+		return
+	}
+	t.duration /= *animSpeed
+	tweens = append(tweens, t)
+}
+
+// animateMove starts the animation for a move that has just been played.
+// from is where the piece sprite was when the move was made, either its
+// origin point or the point where a dragged piece was dropped.
+func animateMove(m rules.Move, piece int, from [2]float32) {
+	to := boardPoint(m.To)
+	switch {
+	case m.IsPlacement():
+		t := newTween(piece, placeDuration, to, to)
+		t.scale = [2]float32{0, 1}
+		t.alpha = [2]float32{0, 1}
+		t.ease = easeOutBack
+		t.hide = m.To
+		addTween(t)
+	case m.IsJump():
+		t := newTween(piece, jumpDuration, from, to)
+		t.arc = jumpArcHeight
+		t.ease = easeInOutSine
+		t.hide = m.To
+		addTween(t)
+
+		at := boardPoint(m.Captured())
+		c := newTween(rules.Goat, captureDuration, at, at)
+		c.scale = [2]float32{1, 0.6}
+		c.alpha = [2]float32{1, 0}
+		c.tint = [4]float32{1, 0.3, 0.3, 1}
+		c.ease = easeInOutSine
+		addTween(c)
+	default:
+		t := newTween(piece, stepDuration, from, to)
+		t.hide = m.To
+		addTween(t)
+	}
+}
+
+// animateSnapBack returns a piece dropped on an illegal point to home,
+// flashing it red so the rejection is visible.
+func animateSnapBack(piece int, from [2]float32, home [2]int) {
+	t := newTween(piece, snapDuration, from, boardPoint(home))
+	t.tint = [4]float32{1, 0.25, 0.25, 1}
+	t.ease = easeOutBack
+	t.hide = home
+	addTween(t)
+}
+
+// animating reports whether any tween is still running.
+func animating() bool {
+	return len(tweens) > 0
+}
+
+// whenAnimationsDone runs fn after the current animations, or right away.
+func whenAnimationsDone(fn func()) {
+	if !animating() {
+		fn()
+		return
+	}
+	afterAnimations = append(afterAnimations, fn)
+}
+
+// skipAnimations jumps every running animation to its end.
+func skipAnimations() {
+	tweens = tweens[:0]
+	runAfterAnimations()
+}
+
+// clearAnimations drops running animations and their pending callbacks.
+func clearAnimations() {
+	tweens = tweens[:0]
+	afterAnimations = nil
+}
+
+// updateAnimations retires finished tweens.
+func updateAnimations(now float64) {
+	if !animating() {
+		return
+	}
+	live := tweens[:0]
+	for _, t := range tweens {
+		if now-t.start < t.duration {
+			live = append(live, t)
+		}
+	}
+	tweens = live
+	if !animating() {
+		runAfterAnimations()
+	}
+}
+
+func runAfterAnimations() {
+	pending := afterAnimations
+	afterAnimations = nil
+	for _, fn := range pending {
+		fn()
+	}
+}
+
+// hiddenByAnimation reports whether the static sprite at a board point is
+// currently being drawn by a tween instead.
+func hiddenByAnimation(x, y int) bool {
+	for _, t := range tweens {
+		if t.hide == [2]int{x, y} {
+			return true
+		}
+	}
+	return false
+}
+
+// drawAnimations draws every running tween at its current state.
+func drawAnimations(now float64) {
+	for _, t := range tweens {
+		raw := (now - t.start) / t.duration
+		if raw < 0 {
+			raw = 0
+		} else if raw > 1 {
+			raw = 1
+		}
+		e := float32(t.ease(raw))
+		x := lerp(t.from[0], t.to[0], e)
+		y := lerp(t.from[1], t.to[1], e)
+		// The arc follows raw progress so the peak sits half-way in time
+		y += t.arc * 4 * float32(raw*(1-raw))
+
+		c := t.tint
+		for i := 0; i < 3; i++ {
+			c[i] = lerp(c[i], 1, float32(raw))
+		}
+		c[3] = lerp(t.alpha[0], t.alpha[1], float32(raw))
+		drawPiece(t.piece, x, y, lerp(t.scale[0], t.scale[1], e), c)
+	}
+}
+
+// boardPoint returns the NDC centre of a board point, with the board
+// turned as it is shown.
+func boardPoint(p [2]int) [2]float32 {
+	return gridPoint(turnPoint(p, boardTurns()))
+}
+
+func lerp(a, b, t float32) float32 {
+	return a + (b-a)*t
+}
+
+func easeOutCubic(t float64) float64 {
+	return 1 - math.Pow(1-t, 3)
+}
+
+func easeInOutSine(t float64) float64 {
+	return -(math.Cos(math.Pi*t) - 1) / 2
+}
+
+// easeOutBack overshoots slightly before settling, for a small bounce.
+func easeOutBack(t float64) float64 {
+	const c1 = 1.70158
+	const c3 = c1 + 1
+	return 1 + c3*math.Pow(t-1, 3) + c1*math.Pow(t-1, 2)
+}
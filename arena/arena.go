@@ -0,0 +1,190 @@
+// Package arena plays engines against each other and measures their
+// relative strength.
+package arena
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/baag_chal_gl/engine"
+	"github.com/baag_chal_gl/rules"
+)
+
+// Config describes a match between engine A and engine B.
+type Config struct {
+	// EngineA and EngineB are engine specs as accepted by engine.Open.
+	EngineA, EngineB string
+	// Games is the maximum number of games; A plays goats in even games
+	// and tigers in odd ones, and each pair shares an opening.
+	Games int
+	// Concurrency is the number of games played at once.
+	Concurrency int
+	// Openings are the start positions, used in turn.
+	Openings []rules.Position
+	// MaxPlies adjudicates a game as a draw when it gets this long.
+	MaxPlies int
+	// MoveTime limits each move; zero leaves it to the engine settings.
+	MoveTime time.Duration
+	// SPRT, if set, stops the match once the test is decided.
+	SPRT *SPRT
+}
+
+// GameResult is one finished game of a match.
+type GameResult struct {
+	Index int
+	// ATiger reports whether engine A played the tigers.
+	ATiger  bool
+	Opening int
+	Result  rules.Result
+	Game    *rules.Game
+	Err     error
+}
+
+// ScoreA is engine A's points from the game.
+func (r GameResult) ScoreA() float64 {
+	switch {
+	case r.Result == rules.Draw:
+		return 0.5
+	case (r.Result == rules.TigersWin) == r.ATiger:
+		return 1
+	}
+	return 0
+}
+
+// Run plays the match, calling report after every finished game with the
+// updated statistics. It stops early when the SPRT is decided or ctx is
+// cancelled.
+func Run(ctx context.Context, cfg Config, report func(GameResult, Stats)) (Stats, error) {
+	if cfg.Concurrency < 1 {
+		cfg.Concurrency = 1
+	}
+	if cfg.MaxPlies <= 0 {
+		cfg.MaxPlies = 300
+	}
+	if len(cfg.Openings) == 0 {
+		cfg.Openings = []rules.Position{rules.NewPosition()}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	jobs := make(chan int)
+	results := make(chan GameResult)
+	var wg sync.WaitGroup
+	for w := 0; w < cfg.Concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			worker(ctx, cfg, jobs, results)
+		}()
+	}
+	go func() {
+		defer close(jobs)
+		for i := 0; i < cfg.Games; i++ {
+			select {
+			case jobs <- i:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	var stats Stats
+	var firstErr error
+	for r := range results {
+		if r.Err != nil {
+			if firstErr == nil {
+				firstErr = r.Err
+				cancel()
+			}
+			continue
+		}
+		side := &stats.AsGoat
+		if r.ATiger {
+			side = &stats.AsTiger
+		}
+		switch r.ScoreA() {
+		case 1:
+			side.Wins++
+		case 0.5:
+			side.Draws++
+		default:
+			side.Losses++
+		}
+		if report != nil {
+			report(r, stats)
+		}
+		if cfg.SPRT != nil && cfg.SPRT.Decision(stats) != 0 {
+			cancel()
+		}
+	}
+	return stats, firstErr
+}
+
+// worker plays games with its own engine instances, since external engines
+// can only play one game at a time.
+func worker(ctx context.Context, cfg Config, jobs <-chan int, results chan<- GameResult) {
+	a, err := engine.Open(cfg.EngineA)
+	if err != nil {
+		results <- GameResult{Err: fmt.Errorf("engine A: %v", err)}
+		return
+	}
+	defer a.Close()
+	b, err := engine.Open(cfg.EngineB)
+	if err != nil {
+		results <- GameResult{Err: fmt.Errorf("engine B: %v", err)}
+		return
+	}
+	defer b.Close()
+
+	for i := range jobs {
+		r := GameResult{Index: i, ATiger: i%2 == 1, Opening: (i / 2) % len(cfg.Openings)}
+		goat, tiger := a, b
+		if r.ATiger {
+			goat, tiger = b, a
+		}
+		r.Game, r.Result, r.Err = PlayGame(ctx, cfg.Openings[r.Opening], goat, tiger, cfg.MaxPlies, cfg.MoveTime)
+		if ctx.Err() != nil {
+			return
+		}
+		results <- r
+	}
+}
+
+// PlayGame plays one game from start. The game is drawn once it reaches
+// maxPlies moves.
+func PlayGame(ctx context.Context, start rules.Position, goat, tiger engine.Engine,
+	maxPlies int, moveTime time.Duration) (*rules.Game, rules.Result, error) {
+	g := rules.NewGame(start)
+	for g.Result() == rules.Ongoing {
+		if err := ctx.Err(); err != nil {
+			return g, rules.Ongoing, err
+		}
+		if g.Ply() >= maxPlies {
+			return g, rules.Draw, nil
+		}
+		e := goat
+		if g.Position().Turn == rules.Tiger {
+			e = tiger
+		}
+		moveCtx, cancel := ctx, context.CancelFunc(func() {})
+		if moveTime > 0 {
+			moveCtx, cancel = context.WithTimeout(ctx, moveTime)
+		}
+		m, err := e.BestMove(moveCtx, g)
+		cancel()
+		if err != nil {
+			return g, rules.Ongoing, fmt.Errorf("%s: %v", e.Name(), err)
+		}
+		if err := g.Play(m); err != nil {
+			return g, rules.Ongoing, fmt.Errorf("%s played illegal move %s: %v", e.Name(), m, err)
+		}
+	}
+	return g, g.Result(), nil
+}
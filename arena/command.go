@@ -0,0 +1,79 @@
+package arena
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+)
+
+// Command holds the flags of the match command, which the game and
+// cmd/arena share.
+type Command struct {
+	Config Config
+	// OpeningsFile holds one opening per line; empty means
+	// DefaultOpenings.
+	OpeningsFile string
+	// SPRT holds the bounds for ParseSPRT; empty runs no test.
+	SPRT string
+	// Quiet leaves out the line printed after each game.
+	Quiet bool
+}
+
+// SetFlags defines the command's flags in fs.
+func (c *Command) SetFlags(fs *flag.FlagSet) {
+	fs.StringVar(&c.Config.EngineA, "a", "hard", "engine A: preset, settings such as depth=5,noise=10, or cmd:<command>")
+	fs.StringVar(&c.Config.EngineB, "b", "medium", "engine B, same forms as -a")
+	fs.IntVar(&c.Config.Games, "games", 100, "maximum number of games")
+	fs.IntVar(&c.Config.Concurrency, "concurrency", runtime.NumCPU(), "games played at once")
+	fs.IntVar(&c.Config.MaxPlies, "maxplies", 300, "adjudicate a draw after this many plies")
+	fs.DurationVar(&c.Config.MoveTime, "movetime", 0, "time limit per move, e.g. 200ms")
+	fs.StringVar(&c.OpeningsFile, "openings", "", "file of openings, one position string or move list per line")
+	fs.StringVar(&c.SPRT, "sprt", "", "run an SPRT with bounds elo0,elo1 (alpha=beta=0.05)")
+	fs.BoolVar(&c.Quiet, "q", false, "only print the final report")
+}
+
+// Run plays the match, printing a line per game and then the report to w.
+// If the match stops early the report covers the games played so far and
+// the error is returned.
+func (c *Command) Run(ctx context.Context, w io.Writer) error {
+	cfg := c.Config
+	cfg.Openings = DefaultOpenings()
+	if c.OpeningsFile != "" {
+		f, err := os.Open(c.OpeningsFile)
+		if err != nil {
+			return err
+		}
+		cfg.Openings, err = ReadOpenings(f)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %v", c.OpeningsFile, err)
+		}
+	}
+	if c.SPRT != "" {
+		sprt, err := ParseSPRT(c.SPRT)
+		if err != nil {
+			return err
+		}
+		cfg.SPRT = &sprt
+	}
+
+	stats, err := Run(ctx, cfg, func(r GameResult, s Stats) {
+		if c.Quiet {
+			return
+		}
+		side := "goats"
+		if r.ATiger {
+			side = "tigers"
+		}
+		fmt.Fprintf(w, "game %d: A as %s, opening %d, %d plies: %s  (A %s)\n",
+			r.Index+1, side, r.Opening+1, r.Game.Ply(), r.Result, s.Total())
+	})
+	WriteReport(w, cfg, stats)
+	if err != nil {
+		return fmt.Errorf("stopped after %d games: %v", stats.Total().Games(), err)
+	}
+	return nil
+}
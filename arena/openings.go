@@ -0,0 +1,76 @@
+package arena
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/baag_chal_gl/rules"
+)
+
+// defaultOpenings are short move sequences from the starting position used
+// when no opening file is given.
+var defaultOpenings = []string{
+	"",
+	"c3",
+	"c1",
+	"a3",
+	"b2",
+	"b3",
+	"c3 a1-b1",
+	"c1 a5-b5 c5",
+	"b1 e5-d4 c3",
+	"a2 e1-e2 d1",
+}
+
+// DefaultOpenings returns the built-in opening positions.
+func DefaultOpenings() []rules.Position {
+	var positions []rules.Position
+	for _, line := range defaultOpenings {
+		p, err := ParseOpening(line)
+		if err != nil {
+			panic(err)
+		}
+		positions = append(positions, p)
+	}
+	return positions
+}
+
+// ParseOpening accepts either a position string or a sequence of moves
+// played from the starting position.
+func ParseOpening(line string) (rules.Position, error) {
+	if strings.Contains(line, "/") {
+		return rules.ParsePosition(line)
+	}
+	g := rules.NewGame(rules.NewPosition())
+	for _, s := range strings.Fields(line) {
+		m, err := rules.ParseMove(s)
+		if err != nil {
+			return rules.Position{}, err
+		}
+		if err := g.Play(m); err != nil {
+			return rules.Position{}, fmt.Errorf("opening %q: %s: %v", line, s, err)
+		}
+	}
+	return g.Position(), nil
+}
+
+// ReadOpenings reads one opening per line, skipping blank lines and lines
+// starting with '#'.
+func ReadOpenings(r io.Reader) ([]rules.Position, error) {
+	var positions []rules.Position
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		p, err := ParseOpening(line)
+		if err != nil {
+			return nil, err
+		}
+		positions = append(positions, p)
+	}
+	return positions, sc.Err()
+}
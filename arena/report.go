@@ -0,0 +1,58 @@
+package arena
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ParseSPRT reads SPRT bounds written as "elo0,elo1", with alpha and beta
+// of 0.05.
+func ParseSPRT(s string) (SPRT, error) {
+	lo, hi, ok := strings.Cut(s, ",")
+	if !ok {
+		return SPRT{}, fmt.Errorf("SPRT bounds want elo0,elo1, got %q", s)
+	}
+	elo0, err := strconv.ParseFloat(lo, 64)
+	if err != nil {
+		return SPRT{}, err
+	}
+	elo1, err := strconv.ParseFloat(hi, 64)
+	if err != nil {
+		return SPRT{}, err
+	}
+	return SPRT{Elo0: elo0, Elo1: elo1, Alpha: 0.05, Beta: 0.05}, nil
+}
+
+// WriteReport writes a table of the results of engine A by side, its Elo
+// difference and the SPRT verdict if one was run.
+func WriteReport(w io.Writer, cfg Config, s Stats) {
+	fmt.Fprintf(w, "\nA: %s\nB: %s\n\n", cfg.EngineA, cfg.EngineB)
+	fmt.Fprintf(w, "%-10s %6s %6s %6s %7s\n", "A played", "wins", "draws", "losses", "score")
+	row := func(name string, r WDL) {
+		fmt.Fprintf(w, "%-10s %6d %6d %6d %6.1f%%\n", name, r.Wins, r.Draws, r.Losses, 100*r.Score())
+	}
+	row("goats", s.AsGoat)
+	row("tigers", s.AsTiger)
+	row("total", s.Total())
+
+	if elo, margin := s.Elo(); math.IsNaN(elo) {
+		fmt.Fprintf(w, "\nElo difference: no games played\n")
+	} else {
+		fmt.Fprintf(w, "\nElo difference: %+.1f +/- %.1f (95%%)\n", elo, margin)
+	}
+	if cfg.SPRT != nil {
+		lower, upper := cfg.SPRT.Bounds()
+		verdict := "inconclusive"
+		switch cfg.SPRT.Decision(s) {
+		case 1:
+			verdict = "H1 accepted"
+		case -1:
+			verdict = "H0 accepted"
+		}
+		fmt.Fprintf(w, "SPRT [%g, %g]: LLR %.2f (%.2f, %.2f) %s\n",
+			cfg.SPRT.Elo0, cfg.SPRT.Elo1, cfg.SPRT.LLR(s), lower, upper, verdict)
+	}
+}
@@ -0,0 +1,122 @@
+package arena
+
+import (
+	"fmt"
+	"math"
+)
+
+// WDL counts wins, draws and losses.
+type WDL struct {
+	Wins, Draws, Losses int
+}
+
+// Games is the number of games counted.
+func (w WDL) Games() int {
+	return w.Wins + w.Draws + w.Losses
+}
+
+// Score is the points scored per game, counting a draw as half a point.
+func (w WDL) Score() float64 {
+	if w.Games() == 0 {
+		return 0
+	}
+	return (float64(w.Wins) + float64(w.Draws)/2) / float64(w.Games())
+}
+
+func (w WDL) String() string {
+	return fmt.Sprintf("+%d =%d -%d", w.Wins, w.Draws, w.Losses)
+}
+
+// Stats are the results of engine A against engine B, split by the side A
+// played.
+type Stats struct {
+	AsGoat, AsTiger WDL
+}
+
+// Total combines both sides.
+func (s Stats) Total() WDL {
+	return WDL{
+		Wins:   s.AsGoat.Wins + s.AsTiger.Wins,
+		Draws:  s.AsGoat.Draws + s.AsTiger.Draws,
+		Losses: s.AsGoat.Losses + s.AsTiger.Losses,
+	}
+}
+
+// eloFromScore converts an expected score to an Elo difference.
+func eloFromScore(score float64) float64 {
+	return -400 * math.Log10(1/score-1)
+}
+
+// scoreFromElo converts an Elo difference to an expected score.
+func scoreFromElo(elo float64) float64 {
+	return 1 / (1 + math.Pow(10, -elo/400))
+}
+
+// variance is the per-game variance of A's score.
+func (w WDL) variance() float64 {
+	n := float64(w.Games())
+	s := w.Score()
+	return (float64(w.Wins)*(1-s)*(1-s) +
+		float64(w.Draws)*(0.5-s)*(0.5-s) +
+		float64(w.Losses)*s*s) / n
+}
+
+// Elo returns A's Elo difference over B and the half-width of its 95%
+// confidence interval. Both are NaN before any game is played, and
+// infinite while A has scored 0% or 100%.
+func (s Stats) Elo() (diff, margin float64) {
+	t := s.Total()
+	if t.Games() == 0 {
+		return math.NaN(), math.NaN()
+	}
+	score := t.Score()
+	if score <= 0 || score >= 1 {
+		return math.Copysign(math.Inf(1), score-0.5), math.Inf(1)
+	}
+	stderr := math.Sqrt(t.variance() / float64(t.Games()))
+	lo := math.Max(score-1.96*stderr, 1e-9)
+	hi := math.Min(score+1.96*stderr, 1-1e-9)
+	return eloFromScore(score), (eloFromScore(hi) - eloFromScore(lo)) / 2
+}
+
+// SPRT describes a sequential probability ratio test of H0: elo = Elo0
+// against H1: elo = Elo1 with error rates Alpha and Beta.
+type SPRT struct {
+	Elo0, Elo1  float64
+	Alpha, Beta float64
+}
+
+// Bounds returns the lower and upper log-likelihood ratio bounds.
+func (t SPRT) Bounds() (lower, upper float64) {
+	return math.Log(t.Beta / (1 - t.Alpha)), math.Log((1 - t.Beta) / t.Alpha)
+}
+
+// LLR is the log-likelihood ratio of the results so far, using the normal
+// approximation of the generalized SPRT.
+func (t SPRT) LLR(s Stats) float64 {
+	w := s.Total()
+	if w.Games() == 0 {
+		return 0
+	}
+	v := w.variance()
+	if v == 0 {
+		return 0
+	}
+	s0, s1 := scoreFromElo(t.Elo0), scoreFromElo(t.Elo1)
+	score := w.Score()
+	return float64(w.Games()) * (s1 - s0) * (2*score - s0 - s1) / (2 * v)
+}
+
+// Decision reports +1 once H1 is accepted, -1 once H0 is accepted and 0
+// while the test must continue.
+func (t SPRT) Decision(s Stats) int {
+	llr := t.LLR(s)
+	lower, upper := t.Bounds()
+	switch {
+	case llr >= upper:
+		return 1
+	case llr <= lower:
+		return -1
+	}
+	return 0
+}
@@ -0,0 +1,99 @@
+package arena
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+)
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 1e-6
+}
+
+func TestElo(t *testing.T) {
+	tests := []struct {
+		name         string
+		s            Stats
+		diff, margin float64
+	}{
+		{"even", Stats{AsGoat: WDL{5, 0, 5}, AsTiger: WDL{5, 0, 5}}, 0, 163.324830},
+		{"70%", Stats{AsGoat: WDL{40, 10, 0}, AsTiger: WDL{20, 10, 20}}, 147.190714, 66.014639},
+		{"30%", Stats{AsGoat: WDL{0, 10, 40}, AsTiger: WDL{20, 10, 20}}, -147.190714, 66.014639},
+		{"all wins", Stats{AsGoat: WDL{Wins: 2}}, math.Inf(1), math.Inf(1)},
+		{"all losses", Stats{AsTiger: WDL{Losses: 2}}, math.Inf(-1), math.Inf(1)},
+	}
+	for _, tt := range tests {
+		diff, margin := tt.s.Elo()
+		// near is false for infinities, so compare them exactly
+		if !near(diff, tt.diff) && diff != tt.diff || !near(margin, tt.margin) && margin != tt.margin {
+			t.Errorf("%s: Elo = %v +/- %v, want %v +/- %v", tt.name, diff, margin, tt.diff, tt.margin)
+		}
+	}
+
+	if diff, margin := (Stats{}).Elo(); !math.IsNaN(diff) || !math.IsNaN(margin) {
+		t.Errorf("no games: Elo = %v +/- %v, want NaN", diff, margin)
+	}
+}
+
+func TestEloScoreRoundTrip(t *testing.T) {
+	for _, elo := range []float64{-400, -35.5, 0, 10, 191.3} {
+		if got := eloFromScore(scoreFromElo(elo)); !near(got, elo) {
+			t.Errorf("eloFromScore(scoreFromElo(%v)) = %v", elo, got)
+		}
+	}
+	if got := scoreFromElo(400); !near(got, 10.0/11) {
+		t.Errorf("scoreFromElo(400) = %v, want 10/11", got)
+	}
+}
+
+func TestSPRT(t *testing.T) {
+	sprt := SPRT{Elo0: 0, Elo1: 10, Alpha: 0.05, Beta: 0.05}
+	lower, upper := sprt.Bounds()
+	if !near(lower, -2.944439) || !near(upper, 2.944439) {
+		t.Errorf("Bounds = %v, %v, want -+2.944439", lower, upper)
+	}
+
+	tests := []struct {
+		name     string
+		s        Stats
+		llr      float64
+		decision int
+	}{
+		{"no games", Stats{}, 0, 0},
+		{"no variance", Stats{AsGoat: WDL{Draws: 8}}, 0, 0},
+		{"even", Stats{AsGoat: WDL{5, 0, 5}, AsTiger: WDL{5, 0, 5}}, -0.008280, 0},
+		{"70%", Stats{AsGoat: WDL{40, 10, 0}, AsTiger: WDL{20, 10, 20}}, 1.733713, 0},
+		{"70% of 200", Stats{AsGoat: WDL{80, 20, 0}, AsTiger: WDL{40, 20, 40}}, 3.467427, 1},
+		{"30% of 200", Stats{AsGoat: WDL{0, 20, 80}, AsTiger: WDL{40, 20, 40}}, -3.726165, -1},
+	}
+	for _, tt := range tests {
+		llr := sprt.LLR(tt.s)
+		if math.Abs(llr-tt.llr) > 1e-3 {
+			t.Errorf("%s: LLR = %v, want %v", tt.name, llr, tt.llr)
+		}
+		if d := sprt.Decision(tt.s); d != tt.decision {
+			t.Errorf("%s: Decision = %d, want %d", tt.name, d, tt.decision)
+		}
+	}
+}
+
+func TestParseSPRT(t *testing.T) {
+	got, err := ParseSPRT("-5,7.5")
+	if want := (SPRT{Elo0: -5, Elo1: 7.5, Alpha: 0.05, Beta: 0.05}); err != nil || got != want {
+		t.Errorf("ParseSPRT = %+v, %v, want %+v", got, err, want)
+	}
+	for _, s := range []string{"", "5", "a,5", "5,b"} {
+		if _, err := ParseSPRT(s); err == nil {
+			t.Errorf("ParseSPRT(%q) succeeded", s)
+		}
+	}
+}
+
+func TestWriteReportNoGames(t *testing.T) {
+	var b bytes.Buffer
+	WriteReport(&b, Config{EngineA: "hard", EngineB: "easy"}, Stats{})
+	if !strings.Contains(b.String(), "Elo difference: no games played") {
+		t.Errorf("report without games:\n%s", b.String())
+	}
+}
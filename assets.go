@@ -0,0 +1,27 @@
+package main
+
+import (
+	"flag"
+	"io/fs"
+	"log"
+
+	"github.com/baag_chal_gl/assets"
+)
+
+var assetDirFlag = flag.String("assets", "", "directory whose files override the built-in assets (default: the user asset directory)")
+
+// assetFS serves every image, font and theme manifest. Paths are slash
+// separated and relative to the assets directory, e.g. "themes/cute/goat.png".
+var assetFS fs.FS = assets.Builtin()
+
+// initAssets layers the user asset directory over the embedded assets.
+func initAssets() {
+	fsys, dir, err := assets.Open(*assetDirFlag)
+	if err != nil {
+		log.Printf("[Assets] Ignoring %v", err)
+	}
+	assetFS = fsys
+	if dir != "" {
+		log.Printf("[Assets] Overlaying %s on the built-in assets", dir)
+	}
+}
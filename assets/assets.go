@@ -0,0 +1,94 @@
+// Package assets holds the game's built-in art, fonts, sounds and theme
+// manifests, and layers a directory of the player's own files over them.
+// It has no cgo dependencies, so headless tools can draw with the same
+// themes as the game.
+package assets
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// The default assets are built into the binary so the game runs from any
+// working directory.
+//
+//go:embed *.ttf *.otf sounds themes
+var embedded embed.FS
+
+// DefaultFont is the built-in font used when a theme's font is missing.
+const DefaultFont = "Wasted-Vindey.ttf"
+
+// Builtin returns the embedded assets. Paths are slash separated and
+// relative to the assets directory, e.g. "themes/cute/goat.png".
+func Builtin() fs.FS {
+	return embedded
+}
+
+// Open layers dir over the built-in assets and reports the directory it
+// used, or "" for none. An empty dir means the user asset directory, which
+// need not exist.
+func Open(dir string) (fsys fs.FS, used string, err error) {
+	explicit := dir != ""
+	if !explicit {
+		dir = UserDir()
+	}
+	if dir == "" {
+		return embedded, "", nil
+	}
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		if explicit {
+			return embedded, "", fmt.Errorf("%s is not a directory", dir)
+		}
+		return embedded, "", nil
+	}
+	return overlayFS{upper: os.DirFS(dir), lower: embedded}, dir, nil
+}
+
+// UserDir is where players drop extra themes or replacement art.
+func UserDir() string {
+	config, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(config, "baag_chal", "assets")
+}
+
+// overlayFS serves files from upper, falling back to lower for anything
+// upper does not have. Directory listings are merged.
+type overlayFS struct {
+	upper, lower fs.FS
+}
+
+func (o overlayFS) Open(name string) (fs.File, error) {
+	f, err := o.upper.Open(name)
+	if err == nil {
+		return f, nil
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	return o.lower.Open(name)
+}
+
+func (o overlayFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	upper, upperErr := fs.ReadDir(o.upper, name)
+	lower, lowerErr := fs.ReadDir(o.lower, name)
+	if upperErr != nil && lowerErr != nil {
+		return nil, lowerErr
+	}
+	seen := map[string]bool{}
+	var merged []fs.DirEntry
+	for _, e := range append(upper, lower...) {
+		if !seen[e.Name()] {
+			seen[e.Name()] = true
+			merged = append(merged, e)
+		}
+	}
+	sort.Slice(merged, func(i, j int) bool { return merged[i].Name() < merged[j].Name() })
+	return merged, nil
+}
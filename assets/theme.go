@@ -0,0 +1,133 @@
+package assets
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"log"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ThemesDir holds one directory per theme in the asset filesystem.
+const ThemesDir = "themes"
+
+// Theme is a theme manifest, read from <ThemesDir>/<id>/theme.json. Image
+// and font paths are relative to the manifest's directory. Missing art is
+// replaced by drawn sprites, so a theme can be just a manifest.
+type Theme struct {
+	ID   string `json:"-"`
+	Name string `json:"name"`
+
+	Goat       string `json:"goat"`
+	Tiger      string `json:"tiger"`
+	Background string `json:"background,omitempty"`
+	Font       string `json:"font"`
+
+	BackgroundColor Color   `json:"backgroundColor"`
+	LineColor       Color   `json:"lineColor"`
+	LineWidth       float32 `json:"lineWidth"`
+	TextColor       Color   `json:"textColor"`
+
+	// Highlight marks the selected piece and its targets, LastMove the
+	// previous move and Threat goats that can be captured.
+	Highlight Color `json:"highlight"`
+	LastMove  Color `json:"lastMove"`
+	Threat    Color `json:"threat"`
+
+	// Sounds replaces default sound effects by name ("place", "capture"
+	// and so on) and Music loops in the background; both are WAV files.
+	Sounds map[string]string `json:"sounds,omitempty"`
+	Music  string            `json:"music,omitempty"`
+
+	// Dir is the manifest's directory in the asset filesystem.
+	Dir string `json:"-"`
+}
+
+// Color is an RGBA colour written as "#rrggbb" or "#rrggbbaa".
+type Color [4]float32
+
+func (c *Color) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	hex := strings.TrimPrefix(s, "#")
+	if len(hex) == 6 {
+		hex += "ff"
+	}
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil || len(hex) != 8 {
+		return fmt.Errorf("bad colour %q", s)
+	}
+	for i := 0; i < 4; i++ {
+		c[i] = float32(v>>(24-8*i)&0xff) / 255
+	}
+	return nil
+}
+
+func (c Color) MarshalJSON() ([]byte, error) {
+	return json.Marshal(fmt.Sprintf("#%02x%02x%02x%02x",
+		uint8(c[0]*255+0.5), uint8(c[1]*255+0.5), uint8(c[2]*255+0.5), uint8(c[3]*255+0.5)))
+}
+
+// DefaultTheme supplies any value a manifest leaves out.
+var DefaultTheme = Theme{
+	ID:              "classic",
+	Name:            "Classic",
+	Goat:            "goat.png",
+	Tiger:           "tiger.png",
+	Font:            "../../" + DefaultFont,
+	BackgroundColor: Color{0, 0, 0, 1},
+	LineColor:       Color{1, 1, 1, 1},
+	LineWidth:       2,
+	TextColor:       Color{1, 1, 1, 1},
+	Highlight:       Color{1, 0.84, 0.29, 0.6},
+	LastMove:        Color{0.29, 0.64, 1, 0.5},
+	Threat:          Color{1, 0.25, 0.25, 0.6},
+	Dir:             path.Join(ThemesDir, "classic"),
+}
+
+// LoadThemes reads every manifest in fsys, sorted by ID. Broken manifests
+// are logged and skipped.
+func LoadThemes(fsys fs.FS) ([]*Theme, error) {
+	entries, err := fs.ReadDir(fsys, ThemesDir)
+	if err != nil {
+		return nil, err
+	}
+	var list []*Theme
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		t, err := ReadTheme(fsys, e.Name())
+		if err != nil {
+			log.Printf("[Theme] Skipping %s: %v", e.Name(), err)
+			continue
+		}
+		list = append(list, t)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	return list, nil
+}
+
+// ReadTheme reads the manifest of the theme with the given ID.
+func ReadTheme(fsys fs.FS, id string) (*Theme, error) {
+	dir := path.Join(ThemesDir, id)
+	data, err := fs.ReadFile(fsys, path.Join(dir, "theme.json"))
+	if err != nil {
+		return nil, err
+	}
+	t := DefaultTheme
+	if err := json.Unmarshal(data, &t); err != nil {
+		return nil, err
+	}
+	t.ID = id
+	t.Dir = dir
+	if t.Name == "" {
+		t.Name = t.ID
+	}
+	return &t, nil
+}
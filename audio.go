@@ -0,0 +1,387 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"math"
+	"path"
+	"sync"
+
+	"github.com/baag_chal_gl/rules"
+)
+
+// Sound effects follow the events of the rules layer. Each event has a
+// sound name; a theme can replace any of them and add looping music. All
+// sounds are mixed in software into one stream that the audio backend
+// pulls, and the null backend simply never pulls it.
+
+var (
+	soundFlag  = flag.Bool("sound", true, "play sound effects")
+	musicFlag  = flag.Bool("music", true, "play the theme's background music")
+	volumeFlag = flag.Float64("volume", 0.8, "sound volume from 0 to 1")
+)
+
+// soundsDir holds the default sounds, <name>.wav, in the asset filesystem.
+const soundsDir = "sounds"
+
+// soundNames are the effects a theme can provide.
+var soundNames = []string{"place", "move", "capture", "illegal", "win", "loss", "lowtime"}
+
+// mixRate is the sample rate of the mixed stream; the stream is 16-bit
+// stereo.
+const mixRate = 44100
+
+// audioBackend plays the mixed stream on a device.
+type audioBackend interface {
+	name() string
+	// start begins pulling samples from src until close.
+	start(src io.Reader) error
+	close()
+}
+
+// nullAudio is the silent backend used without a sound device, when
+// sound is off and in builds tagged noaudio.
+type nullAudio struct{}
+
+func (nullAudio) name() string          { return "none" }
+func (nullAudio) start(io.Reader) error { return nil }
+func (nullAudio) close()                {}
+
+var audio = struct {
+	backend audioBackend
+	mix     *mixer
+	// effects holds the current theme's decoded sounds by name
+	effects map[string][]float32
+	// musicPath is the theme music playing, so reapplying a theme does
+	// not restart it
+	musicPath string
+}{backend: nullAudio{}, mix: &mixer{volume: 0.8}}
+
+// initAudio opens the sound device, falling back to silence when there is
+// none.
+func initAudio() {
+	audio.mix.setVolume(*volumeFlag)
+	audio.mix.musicOff = !*musicFlag
+	// Sound off only mutes, so it can be turned back on in game
+	audio.mix.muted = !*soundFlag
+	b, err := openAudioDevice()
+	if err == nil {
+		err = b.start(audio.mix)
+	}
+	if err != nil {
+		log.Printf("[Sound] No audio: %v", err)
+		return
+	}
+	audio.backend = b
+	log.Printf("[Sound] Using %s", b.name())
+}
+
+// loadThemeSounds decodes the sounds and music of t. Missing sounds fall
+// back to the defaults; missing defaults are silent.
+func loadThemeSounds(t *Theme) {
+	effects := map[string][]float32{}
+	for _, name := range soundNames {
+		file := path.Join(soundsDir, name+".wav")
+		if f, ok := t.Sounds[name]; ok {
+			file = path.Join(t.Dir, f)
+		}
+		samples, err := loadSound(file)
+		if err != nil {
+			log.Printf("[Sound] %s: %v", name, err)
+			continue
+		}
+		effects[name] = samples
+	}
+	audio.effects = effects
+
+	music := ""
+	if t.Music != "" {
+		music = path.Join(t.Dir, t.Music)
+	}
+	if music == audio.musicPath {
+		return
+	}
+	audio.musicPath = music
+	var samples []float32
+	if music != "" {
+		var err error
+		if samples, err = loadSound(music); err != nil {
+			log.Printf("[Sound] music: %v", err)
+		}
+	}
+	audio.mix.setMusic(samples)
+}
+
+// loadSound reads a WAV file from the asset filesystem.
+func loadSound(file string) ([]float32, error) {
+	data, err := fs.ReadFile(assetFS, file)
+	if err != nil {
+		return nil, err
+	}
+	return decodeWAV(data)
+}
+
+// playSound starts the named effect.
+func playSound(name string) {
+	if s, ok := audio.effects[name]; ok {
+		audio.mix.play(s)
+	}
+}
+
+// onGameEvent plays the sound for a rules event.
+func onGameEvent(e rules.Event) {
+	if name := soundFor(e); name != "" {
+		playSound(name)
+	}
+}
+
+// soundFor maps an event to a sound name. A result is a win unless the
+// computer won it.
+func soundFor(e rules.Event) string {
+	switch e {
+	case rules.EventPlace:
+		return "place"
+	case rules.EventMove:
+		return "move"
+	case rules.EventCapture:
+		return "capture"
+	case rules.EventIllegal:
+		return "illegal"
+	case rules.EventGoatsWin, rules.EventTigersWin:
+		winner := rules.Goat
+		if e == rules.EventTigersWin {
+			winner = rules.Tiger
+		}
+		if ai.side == winner {
+			return "loss"
+		}
+		return "win"
+	case rules.EventClockLow:
+		return "lowtime"
+	}
+	return ""
+}
+
+// toggleMute turns all sound off or back on.
+func toggleMute() {
+	m := audio.mix
+	m.mu.Lock()
+	m.muted = !m.muted
+	muted := m.muted
+	m.mu.Unlock()
+	log.Printf("[Sound] Muted: %v", muted)
+}
+
+// toggleMusic turns the background music off or back on.
+func toggleMusic() {
+	m := audio.mix
+	m.mu.Lock()
+	m.musicOff = !m.musicOff
+	m.mu.Unlock()
+}
+
+// cycleVolume steps the volume up a quarter, back to silent after full.
+func cycleVolume() {
+	v := math.Round(audio.mix.getVolume()*4)/4 + 0.25
+	if v > 1 {
+		v = 0
+	}
+	audio.mix.setVolume(v)
+}
+
+// mixer sums the playing sounds into a 16-bit stereo stream. It is read
+// from the backend's goroutine, so every field is guarded by mu.
+type mixer struct {
+	mu       sync.Mutex
+	voices   []*voice
+	music    *voice
+	volume   float32
+	muted    bool
+	musicOff bool
+}
+
+type voice struct {
+	samples []float32
+	pos     int
+}
+
+// maxVoices bounds the effects playing at once; the oldest is dropped.
+const maxVoices = 8
+
+// musicGain keeps the music under the effects.
+const musicGain = 0.5
+
+func (m *mixer) play(samples []float32) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.voices) >= maxVoices {
+		m.voices = m.voices[1:]
+	}
+	m.voices = append(m.voices, &voice{samples: samples})
+}
+
+func (m *mixer) setMusic(samples []float32) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.music = nil
+	if len(samples) > 0 {
+		m.music = &voice{samples: samples}
+	}
+}
+
+// setVolume sets the volume, kept between 0 and 1.
+func (m *mixer) setVolume(v float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.volume = float32(min(max(v, 0), 1))
+}
+
+// state reports whether sound is muted and whether music is off.
+func (m *mixer) state() (muted, musicOff bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.muted, m.musicOff
+}
+
+func (m *mixer) getVolume() float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return float64(m.volume)
+}
+
+// Read fills p with whole frames of mixed sound. It never ends.
+func (m *mixer) Read(p []byte) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	frames := len(p) / 4
+	gain := m.volume
+	if m.muted {
+		gain = 0
+	}
+	for i := 0; i < frames; i++ {
+		var s float32
+		for _, v := range m.voices {
+			if v.pos < len(v.samples) {
+				s += v.samples[v.pos]
+				v.pos++
+			}
+		}
+		if m.music != nil {
+			if !m.musicOff {
+				s += m.music.samples[m.music.pos] * musicGain
+			}
+			m.music.pos = (m.music.pos + 1) % len(m.music.samples)
+		}
+		s = min(max(s*gain, -1), 1)
+		v := uint16(int16(s * 32767))
+		binary.LittleEndian.PutUint16(p[i*4:], v)
+		binary.LittleEndian.PutUint16(p[i*4+2:], v)
+	}
+	live := m.voices[:0]
+	for _, v := range m.voices {
+		if v.pos < len(v.samples) {
+			live = append(live, v)
+		}
+	}
+	m.voices = live
+	return frames * 4, nil
+}
+
+// decodeWAV decodes 8- or 16-bit PCM WAV data to mono samples at mixRate.
+func decodeWAV(data []byte) ([]float32, error) {
+	r := bytes.NewReader(data)
+	var riff struct {
+		ID   [4]byte
+		Size uint32
+		Wave [4]byte
+	}
+	if err := binary.Read(r, binary.LittleEndian, &riff); err != nil {
+		return nil, err
+	}
+	if string(riff.ID[:]) != "RIFF" || string(riff.Wave[:]) != "WAVE" {
+		return nil, errors.New("not a WAV file")
+	}
+
+	var format struct {
+		Format, Channels uint16
+		Rate, ByteRate   uint32
+		Align, Bits      uint16
+	}
+	var pcm []byte
+	for pcm == nil {
+		var chunk struct {
+			ID   [4]byte
+			Size uint32
+		}
+		if err := binary.Read(r, binary.LittleEndian, &chunk); err != nil {
+			return nil, errors.New("WAV file has no data")
+		}
+		// The size comes from the file, so check it before allocating
+		if int64(chunk.Size) > int64(r.Len()) {
+			return nil, fmt.Errorf("WAV %q chunk of %d bytes with %d left", chunk.ID[:], chunk.Size, r.Len())
+		}
+		body := make([]byte, chunk.Size)
+		if _, err := io.ReadFull(r, body); err != nil {
+			return nil, err
+		}
+		if chunk.Size%2 == 1 {
+			r.ReadByte()
+		}
+		switch string(chunk.ID[:]) {
+		case "fmt ":
+			if err := binary.Read(bytes.NewReader(body), binary.LittleEndian, &format); err != nil {
+				return nil, err
+			}
+		case "data":
+			pcm = body
+		}
+	}
+	if format.Format != 1 || format.Channels == 0 || format.Rate == 0 || (format.Bits != 8 && format.Bits != 16) {
+		return nil, fmt.Errorf("unsupported WAV format %d, %d channels, %d bits", format.Format, format.Channels, format.Bits)
+	}
+
+	// Average the channels down to mono
+	width := int(format.Bits / 8)
+	channels := int(format.Channels)
+	n := len(pcm) / (width * channels)
+	mono := make([]float32, n)
+	for i := range mono {
+		var sum float32
+		for c := 0; c < channels; c++ {
+			off := (i*channels + c) * width
+			if width == 1 {
+				sum += (float32(pcm[off]) - 128) / 128
+			} else {
+				sum += float32(int16(binary.LittleEndian.Uint16(pcm[off:]))) / 32768
+			}
+		}
+		mono[i] = sum / float32(channels)
+	}
+	return resample(mono, int(format.Rate), mixRate), nil
+}
+
+// resample converts samples between rates by linear interpolation.
+func resample(in []float32, from, to int) []float32 {
+	if from == to || len(in) == 0 {
+		return in
+	}
+	out := make([]float32, int(int64(len(in))*int64(to)/int64(from)))
+	step := float64(from) / float64(to)
+	for i := range out {
+		x := float64(i) * step
+		j := int(x)
+		if j+1 >= len(in) {
+			out[i] = in[len(in)-1]
+			continue
+		}
+		f := float32(x - float64(j))
+		out[i] = in[j] + (in[j+1]-in[j])*f
+	}
+	return out
+}
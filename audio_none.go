@@ -0,0 +1,10 @@
+//go:build noaudio
+
+package main
+
+import "errors"
+
+// Builds tagged noaudio have no sound device and always use nullAudio.
+func openAudioDevice() (audioBackend, error) {
+	return nil, errors.New("built without audio")
+}
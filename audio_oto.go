@@ -0,0 +1,45 @@
+//go:build !noaudio
+
+package main
+
+import (
+	"io"
+	"time"
+
+	"github.com/ebitengine/oto/v3"
+)
+
+// otoAudio plays through the system's sound device with oto.
+type otoAudio struct {
+	ctx    *oto.Context
+	player *oto.Player
+}
+
+func openAudioDevice() (audioBackend, error) {
+	ctx, ready, err := oto.NewContext(&oto.NewContextOptions{
+		SampleRate:   mixRate,
+		ChannelCount: 2,
+		Format:       oto.FormatSignedInt16LE,
+		// Short enough that effects follow moves without a noticeable lag
+		BufferSize: 60 * time.Millisecond,
+	})
+	if err != nil {
+		return nil, err
+	}
+	<-ready
+	return &otoAudio{ctx: ctx}, nil
+}
+
+func (*otoAudio) name() string { return "system audio" }
+
+func (a *otoAudio) start(src io.Reader) error {
+	a.player = a.ctx.NewPlayer(src)
+	a.player.Play()
+	return nil
+}
+
+func (a *otoAudio) close() {
+	if a.player != nil {
+		a.player.Close()
+	}
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+// wav builds a WAV file from RIFF chunks given as id and body pairs.
+func wav(chunks ...any) []byte {
+	var b bytes.Buffer
+	b.WriteString("RIFF\x00\x00\x00\x00WAVE")
+	for i := 0; i < len(chunks); i += 2 {
+		body := chunks[i+1].([]byte)
+		b.WriteString(chunks[i].(string))
+		binary.Write(&b, binary.LittleEndian, uint32(len(body)))
+		b.Write(body)
+		if len(body)%2 == 1 {
+			b.WriteByte(0)
+		}
+	}
+	return b.Bytes()
+}
+
+// pcmFormat is a fmt chunk body for PCM at mixRate.
+func pcmFormat(channels, bits uint16) []byte {
+	align := channels * bits / 8
+	format := struct {
+		Format, Channels uint16
+		Rate, ByteRate   uint32
+		Align, Bits      uint16
+	}{1, channels, mixRate, mixRate * uint32(align), align, bits}
+	var b bytes.Buffer
+	binary.Write(&b, binary.LittleEndian, format)
+	return b.Bytes()
+}
+
+func TestDecodeWAV(t *testing.T) {
+	// A data chunk that claims 4 GiB with only 4 bytes behind it
+	huge := wav("fmt ", pcmFormat(1, 16))
+	huge = append(huge, "data\xff\xff\xff\xff\x00\x00\x00\x00"...)
+
+	tests := []struct {
+		name    string
+		data    []byte
+		samples int
+		ok      bool
+	}{
+		{"16-bit mono", wav("fmt ", pcmFormat(1, 16), "data", make([]byte, 8)), 4, true},
+		{"8-bit stereo", wav("fmt ", pcmFormat(2, 8), "data", make([]byte, 6)), 3, true},
+		{"odd chunk skipped", wav("LIST", []byte("abc"), "fmt ", pcmFormat(1, 8), "data", []byte{128}), 1, true},
+		{"empty", nil, 0, false},
+		{"not RIFF", []byte("RIFX\x00\x00\x00\x00WAVE"), 0, false},
+		{"no data", wav("fmt ", pcmFormat(1, 16)), 0, false},
+		{"no format", wav("data", make([]byte, 4)), 0, false},
+		{"24-bit", wav("fmt ", pcmFormat(1, 24), "data", make([]byte, 6)), 0, false},
+		{"short format", wav("fmt ", []byte{1, 0}, "data", make([]byte, 4)), 0, false},
+		{"oversized chunk", huge, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			samples, err := decodeWAV(tt.data)
+			if (err == nil) != tt.ok {
+				t.Fatalf("decodeWAV: error %v, want ok %v", err, tt.ok)
+			}
+			if len(samples) != tt.samples {
+				t.Errorf("got %d samples, want %d", len(samples), tt.samples)
+			}
+		})
+	}
+}
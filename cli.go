@@ -0,0 +1,203 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/baag_chal_gl/arena"
+	"github.com/baag_chal_gl/engine"
+	"github.com/baag_chal_gl/rules"
+	"github.com/baag_chal_gl/server"
+)
+
+// The program is a set of commands sharing one flag system: the game's
+// flags are the global ones, and a command that needs, say, the theme or
+// the computer's level registers the same flag, so it reads the same saved
+// setting and takes the same value.
+
+type command struct {
+	name, summary string
+	run           func(args []string) int
+}
+
+var commands = []command{
+	{"play", "play on the board (the default)", runPlay},
+	{"serve", "serve the game management HTTP API", runServe},
+	{"engine", "run the engine over the engine protocol on stdin/stdout", runEngine},
+	{"match", "play two engines against each other", runMatch},
+	{"analyze", "annotate a game record with the engine's choices", runAnalyze},
+	{"render", "draw a position as a PNG diagram", runRender},
+	{"replay", "export a game record as an animated GIF or APNG", runReplay},
+	{"perft", "count the move sequences from a position", runPerft},
+}
+
+// play flags that are not settings
+var (
+	positionFlag = flag.String("position", "", "position string to start from instead of the usual start")
+	variantFlag  = flag.String("variant", "standard", "rules variant; only standard is implemented")
+)
+
+func usage() {
+	out := flag.CommandLine.Output()
+	fmt.Fprintln(out, "usage: baag_chal_gl [command] [flags] [arguments]")
+	fmt.Fprintln(out, "\ncommands:")
+	for _, c := range commands {
+		fmt.Fprintf(out, "  %-8s %s\n", c.name, c.summary)
+	}
+	fmt.Fprintln(out, "\nRun baag_chal_gl <command> -h for the flags of a command. The flags of play:")
+	flag.PrintDefaults()
+}
+
+// newFlagSet makes the flag set of a command. The shared flags are the
+// global flags of those names, and set the same variables; naming one that
+// does not exist is a programming error, as redefining a flag is.
+func newFlagSet(name, arguments string, shared ...string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	for _, n := range shared {
+		f := flag.Lookup(n)
+		if f == nil {
+			panic(fmt.Sprintf("%s: no global flag %q to share", name, n))
+		}
+		fs.Var(f.Value, f.Name, f.Usage)
+	}
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: baag_chal_gl %s [flags] %s\n", name, arguments)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// initStartPosition applies the -position and -variant flags.
+func initStartPosition() error {
+	if *variantFlag != "standard" {
+		return fmt.Errorf("unknown variant %q", *variantFlag)
+	}
+	if *positionFlag == "" || *positionFlag == "start" {
+		return nil
+	}
+	p, err := rules.ParsePosition(*positionFlag)
+	if err != nil {
+		return err
+	}
+	startPosition = p
+	return nil
+}
+
+// readInput reads a file, or standard input for "-".
+func readInput(name string) ([]byte, error) {
+	if name == "-" {
+		return io.ReadAll(os.Stdin)
+	}
+	return os.ReadFile(name)
+}
+
+// defaultServeAddr is where serve listens until an address is saved.
+const defaultServeAddr = ":8080"
+
+func runServe(args []string) int {
+	fs := newFlagSet("serve", "")
+	addr := fs.String("addr", defaultServeAddr, "listen address; the last one used is the default next time")
+	fs.Parse(args)
+	if fs.NArg() > 0 {
+		fs.Usage()
+		return 2
+	}
+	loadSettings(fs)
+
+	l, err := net.Listen("tcp", *addr)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "serve:", err)
+		return 1
+	}
+	saveServer(*addr)
+	log.Printf("Serving the game API on %s (spec at /openapi.yaml)", *addr)
+	if err := http.Serve(l, server.New()); err != nil {
+		fmt.Fprintln(os.Stderr, "serve:", err)
+		return 1
+	}
+	return 0
+}
+
+func runEngine(args []string) int {
+	fs := newFlagSet("engine", "", "level")
+	fs.Parse(args)
+	if fs.NArg() > 0 {
+		fs.Usage()
+		return 2
+	}
+	loadSettings(fs)
+
+	cfg, err := engine.ParseConfig(*levelFlag)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "engine:", err)
+		return 2
+	}
+	if err := engine.Serve(os.Stdin, os.Stdout, engine.NewSearcher(cfg)); err != nil {
+		fmt.Fprintln(os.Stderr, "engine:", err)
+		return 1
+	}
+	return 0
+}
+
+func runMatch(args []string) int {
+	fs := newFlagSet("match", "")
+	var cmd arena.Command
+	cmd.SetFlags(fs)
+	fs.Parse(args)
+	if fs.NArg() > 0 {
+		fs.Usage()
+		return 2
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if err := cmd.Run(ctx, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "match:", err)
+		return 1
+	}
+	return 0
+}
+
+func runPerft(args []string) int {
+	fs := newFlagSet("perft", "[position|start]")
+	depth := fs.Int("depth", 5, "deepest number of plies to count")
+	divide := fs.Bool("divide", false, "count below each first move at -depth only")
+	fs.Parse(args)
+	if fs.NArg() > 1 || *depth < 1 {
+		fs.Usage()
+		return 2
+	}
+	p := rules.NewPosition()
+	if fs.NArg() == 1 && fs.Arg(0) != "start" {
+		var err error
+		if p, err = rules.ParsePosition(fs.Arg(0)); err != nil {
+			fmt.Fprintln(os.Stderr, "perft:", err)
+			return 2
+		}
+	}
+
+	if *divide {
+		var total uint64
+		for _, c := range rules.Divide(p, *depth) {
+			fmt.Printf("%-6s %d\n", c.Move, c.Nodes)
+			total += c.Nodes
+		}
+		fmt.Printf("\nmoves %d, nodes %d\n", len(p.LegalMoves()), total)
+		return 0
+	}
+	for d := 1; d <= *depth; d++ {
+		start := time.Now()
+		n := rules.Perft(p, d)
+		elapsed := time.Since(start)
+		fmt.Printf("perft %2d: %12d  %8.3fs  %6.2f Mnps\n", d, n, elapsed.Seconds(), float64(n)/max(elapsed.Seconds(), 1e-9)/1e6)
+	}
+	return 0
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/baag_chal_gl/rules"
+)
+
+// The optional game clock gives each side a time budget plus an increment
+// per move. A side that runs out loses; the rules know nothing about it.
+
+var clockFlag = flag.String("clock", "off", "time control as minutes+increment in seconds, such as 5+3, or off")
+
+// timeControl is a starting time per side and the time added after each
+// move. The zero value is no clock.
+type timeControl struct {
+	base, increment time.Duration
+}
+
+// timeControls are the choices offered in the settings.
+var timeControls = []string{"off", "3+2", "5+3", "10+5", "15+10"}
+
+// parseTimeControl reads "minutes+seconds", "minutes" or "off".
+func parseTimeControl(s string) (timeControl, error) {
+	s = strings.TrimSpace(s)
+	if s == "" || s == "off" {
+		return timeControl{}, nil
+	}
+	base, inc, _ := strings.Cut(s, "+")
+	m, err := strconv.ParseFloat(base, 64)
+	if err != nil || m <= 0 {
+		return timeControl{}, fmt.Errorf("bad time control %q", s)
+	}
+	tc := timeControl{base: time.Duration(m * float64(time.Minute))}
+	if inc != "" {
+		sec, err := strconv.Atoi(inc)
+		if err != nil || sec < 0 {
+			return timeControl{}, fmt.Errorf("bad time control %q", s)
+		}
+		tc.increment = time.Duration(sec) * time.Second
+	}
+	return tc, nil
+}
+
+func (tc timeControl) String() string {
+	if tc.base == 0 {
+		return "off"
+	}
+	return fmt.Sprintf("%g+%d", tc.base.Minutes(), int(tc.increment.Seconds()))
+}
+
+// lowTime is when the clock-low warning sounds.
+const lowTime = 10 * time.Second
+
+var clock = struct {
+	control timeControl
+	// left is the time remaining, indexed by side
+	left   [3]time.Duration
+	warned [3]bool
+	// lastTick is the time of the last update, or 0 to start counting at
+	// the next one
+	lastTick float64
+}{}
+
+// initClock applies the -clock flag.
+func initClock() {
+	tc, err := parseTimeControl(*clockFlag)
+	if err != nil {
+		log.Printf("[Clock] %v, playing without a clock", err)
+	}
+	setTimeControl(tc)
+}
+
+// setTimeControl changes the time control and restarts both clocks.
+func setTimeControl(tc timeControl) {
+	clock.control = tc
+	resetClock()
+}
+
+// cycleTimeControl switches to the next of timeControls.
+func cycleTimeControl() {
+	next := timeControls[0]
+	for i, s := range timeControls {
+		if s == clock.control.String() && i+1 < len(timeControls) {
+			next = timeControls[i+1]
+		}
+	}
+	tc, _ := parseTimeControl(next)
+	setTimeControl(tc)
+}
+
+// resetClock gives both sides their full time.
+func resetClock() {
+	for _, side := range []int{rules.Goat, rules.Tiger} {
+		clock.left[side] = clock.control.base
+		clock.warned[side] = false
+	}
+	clock.lastTick = 0
+}
+
+// pressClock adds the increment for side after it moved.
+func pressClock(side int) {
+	if clock.control.base > 0 {
+		clock.left[side] += clock.control.increment
+	}
+}
+
+// updateClock runs the clock of the side to move. It is called every
+// frame.
+func updateClock(now float64) {
+	last := clock.lastTick
+	clock.lastTick = now
+	if clock.control.base == 0 || gameOver || last == 0 {
+		return
+	}
+	side := history.Position().Turn
+	clock.left[side] -= time.Duration((now - last) * float64(time.Second))
+	if clock.left[side] <= lowTime && !clock.warned[side] {
+		clock.warned[side] = true
+		onGameEvent(rules.EventClockLow)
+	}
+	if clock.left[side] <= 0 {
+		clock.left[side] = 0
+		timeOut(side)
+	}
+}
+
+// timeOut ends the game when side has run out of time.
+func timeOut(side int) {
+	winner := rules.Opponent(side)
+	gameOver = true
+	stopAI()
+	title := sideName(winner) + " win on time!"
+	message := sideName(side) + " ran out of time."
+	log.Printf("[Clock] %s", message)
+	announce(title + " " + message)
+	if winner == rules.Tiger {
+		onGameEvent(rules.EventTigersWin)
+	} else {
+		onGameEvent(rules.EventGoatsWin)
+	}
+	pushDialog(&dialog{
+		Title:     "Game Over - " + title,
+		Message:   message + " Start a new game?",
+		IconPiece: winner,
+		Buttons: []dialogButton{
+			{Label: "New game", Color: okColor, Default: true, OnClick: resetGame},
+			{Label: "Close", Color: cancelColor, Cancel: true},
+		},
+	})
+}
+
+// clockText shows both clocks for the banner, or nothing without a clock.
+func clockText() string {
+	if clock.control.base == 0 {
+		return ""
+	}
+	return fmt.Sprintf("Goats %s  Tigers %s", formatClock(clock.left[rules.Goat]), formatClock(clock.left[rules.Tiger]))
+}
+
+// formatClock shows minutes and seconds, and tenths under ten seconds.
+func formatClock(d time.Duration) string {
+	if d < lowTime {
+		return fmt.Sprintf("%.1f", d.Seconds())
+	}
+	s := int(d.Round(time.Second).Seconds())
+	return fmt.Sprintf("%d:%02d", s/60, s%60)
+}
@@ -0,0 +1,29 @@
+// Command arena plays two engines against each other and reports their
+// results and Elo difference.
+//
+//	arena -a hard -b medium -games 200 -concurrency 4
+//	arena -a "cmd:./myengine" -b hard -sprt 0,10
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+
+	"github.com/baag_chal_gl/arena"
+)
+
+func main() {
+	var cmd arena.Command
+	cmd.SetFlags(flag.CommandLine)
+	flag.Parse()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if err := cmd.Run(ctx, os.Stdout); err != nil {
+		log.Fatalln(err)
+	}
+}
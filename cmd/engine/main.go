@@ -0,0 +1,24 @@
+// Command engine runs a built-in engine over the engine protocol on
+// stdin/stdout, so it can be driven by the arena or other controllers.
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+
+	"github.com/baag_chal_gl/engine"
+)
+
+func main() {
+	spec := flag.String("engine", "hard", "preset or settings such as depth=5,noise=10")
+	flag.Parse()
+
+	cfg, err := engine.ParseConfig(*spec)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	if err := engine.Serve(os.Stdin, os.Stdout, engine.NewSearcher(cfg)); err != nil {
+		log.Fatalln(err)
+	}
+}
@@ -0,0 +1,39 @@
+// Command render draws a position string, or "start", to a PNG file.
+//
+//	render -o puzzle.png -coords -arrow a1xc3 "T3T/5/2G2/5/T3T t 1 0"
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/baag_chal_gl/assets"
+	"github.com/baag_chal_gl/diagram"
+)
+
+func main() {
+	var cmd diagram.RenderCommand
+	cmd.SetFlags(flag.CommandLine)
+	theme := flag.String("theme", "classic", "board theme, one of the directories in assets/"+assets.ThemesDir)
+	assetDir := flag.String("assets", "", "directory whose files override the built-in assets (default: the user asset directory)")
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: render [flags] position|start")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 || cmd.Size < 16 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	fsys, _, err := assets.Open(*assetDir)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "render:", err)
+		os.Exit(1)
+	}
+	if err := cmd.Run(fsys, *theme, flag.Arg(0)); err != nil {
+		fmt.Fprintln(os.Stderr, "render:", err)
+		os.Exit(1)
+	}
+}
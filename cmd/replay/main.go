@@ -0,0 +1,40 @@
+// Command replay turns a game record into an animated GIF, or an APNG when
+// the output ends in .png.
+//
+//	replay -o game.gif -delay 800ms game.txt
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/baag_chal_gl/assets"
+	"github.com/baag_chal_gl/diagram"
+)
+
+func main() {
+	var cmd diagram.ReplayCommand
+	cmd.SetFlags(flag.CommandLine)
+	theme := flag.String("theme", "classic", "board theme, one of the directories in assets/"+assets.ThemesDir)
+	assetDir := flag.String("assets", "", "directory whose files override the built-in assets (default: the user asset directory)")
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: replay [flags] record-file|-")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 || cmd.Size < 16 || cmd.Delay <= 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	fsys, _, err := assets.Open(*assetDir)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "replay:", err)
+		os.Exit(1)
+	}
+	if err := cmd.Run(fsys, *theme, flag.Arg(0)); err != nil {
+		fmt.Fprintln(os.Stderr, "replay:", err)
+		os.Exit(1)
+	}
+}
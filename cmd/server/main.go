@@ -0,0 +1,20 @@
+// Command server serves the game management HTTP API.
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+
+	"github.com/baag_chal_gl/server"
+)
+
+func main() {
+	addr := flag.String("addr", ":8080", "listen address")
+	flag.Parse()
+
+	log.Printf("Serving the game API on %s (spec at /openapi.yaml)", *addr)
+	if err := http.ListenAndServe(*addr, server.New()); err != nil {
+		log.Fatalln(err)
+	}
+}
@@ -0,0 +1,195 @@
+// Command tournament manages round-robin and Swiss tournaments stored in a
+// JSON file.
+//
+//	tournament -f club.json new -format swiss -rounds 5 alice bob hardbot=hard easybot=easy
+//	tournament -f club.json pair
+//	tournament -f club.json games
+//	tournament -f club.json play
+//	tournament -f club.json result 3 tigers
+//	tournament -f club.json import finished.txt
+//	tournament -f club.json standings
+//	tournament -f club.json crosstable
+//	tournament -f club.json export > games.txt
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+
+	"github.com/baag_chal_gl/rules"
+	"github.com/baag_chal_gl/tournament"
+)
+
+func main() {
+	path := flag.String("f", "tournament.json", "tournament file")
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: tournament [-f file] new|pair|games|play|result|import|standings|crosstable|export [args]")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	cmd, args := flag.Arg(0), flag.Args()[1:]
+
+	if cmd == "new" {
+		if err := newTournament(*path, args); err != nil {
+			log.Fatalln(err)
+		}
+		return
+	}
+
+	t, err := tournament.Load(*path)
+	if err != nil {
+		log.Fatalln("failed to load tournament:", err)
+	}
+	changed := false
+	switch cmd {
+	case "pair":
+		games, err := t.PairNextRound()
+		if err != nil {
+			log.Fatalln(err)
+		}
+		printGames(t, games)
+		changed = true
+	case "games":
+		printGames(t, t.Games)
+	case "play":
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+		err = t.PlayBotGames(ctx, func(i int, g tournament.Game) {
+			fmt.Printf("game %d: %s (goats) vs %s (tigers): %s\n",
+				i+1, t.Players[g.Goat].Name, t.Players[g.Tiger].Name, g.Result)
+		})
+		changed = true
+	case "result":
+		err = enterResult(t, args)
+		changed = true
+	case "import":
+		err = importRecords(t, args)
+		changed = true
+	case "standings":
+		t.WriteStandings(os.Stdout)
+	case "crosstable":
+		t.WriteCrosstable(os.Stdout)
+	case "export":
+		err = t.ExportRecords(os.Stdout)
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+	if changed {
+		// Save whatever was entered, even if a later step failed.
+		if serr := t.Save(*path); serr != nil {
+			log.Fatalln("failed to save tournament:", serr)
+		}
+	}
+	if err != nil {
+		log.Fatalln(err)
+	}
+	if t.Finished() && (cmd == "play" || cmd == "result" || cmd == "import") {
+		fmt.Println("All rounds are complete.")
+	}
+}
+
+// newTournament creates the file. Players are given as "name" for humans
+// or "name=engine" for bots.
+func newTournament(path string, args []string) error {
+	fs := flag.NewFlagSet("new", flag.ExitOnError)
+	name := fs.String("name", "Baag-Chal tournament", "event name")
+	format := fs.String("format", tournament.RoundRobin, "roundrobin or swiss")
+	rounds := fs.Int("rounds", 5, "number of Swiss rounds")
+	bothSides := fs.Bool("both-sides", true, "play every pairing once with each side")
+	fs.Parse(args)
+
+	var players []tournament.Player
+	for _, arg := range fs.Args() {
+		n, spec, _ := strings.Cut(arg, "=")
+		players = append(players, tournament.Player{Name: n, Engine: spec})
+	}
+	t, err := tournament.New(*name, *format, *rounds, *bothSides, players)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("%s already exists", path)
+	}
+	if err := t.Save(path); err != nil {
+		return err
+	}
+	fmt.Printf("Created %s with %d players.\n", path, len(players))
+	if t.Format == tournament.RoundRobin {
+		printGames(t, t.Games)
+	}
+	return nil
+}
+
+func printGames(t *tournament.Tournament, games []tournament.Game) {
+	for _, g := range games {
+		i := indexOf(t, g)
+		if g.IsBye() {
+			fmt.Printf("%4d  round %d  %s has a bye\n", i+1, g.Round, t.Players[g.Goat].Name)
+			continue
+		}
+		result := g.Result
+		if result == "" {
+			result = "*"
+		}
+		fmt.Printf("%4d  round %d  %-20s (goats) vs %-20s (tigers)  %s\n",
+			i+1, g.Round, t.Players[g.Goat].Name, t.Players[g.Tiger].Name, result)
+	}
+}
+
+func indexOf(t *tournament.Tournament, g tournament.Game) int {
+	for i, h := range t.Games {
+		if h.Round == g.Round && h.Goat == g.Goat && h.Tiger == g.Tiger {
+			return i
+		}
+	}
+	return -1
+}
+
+func enterResult(t *tournament.Tournament, args []string) error {
+	if len(args) != 2 {
+		return fmt.Errorf("usage: result <game number> goats|tigers|draw")
+	}
+	n, err := strconv.Atoi(args[0])
+	if err != nil {
+		return fmt.Errorf("bad game number %q", args[0])
+	}
+	result, err := rules.ParseResult(args[1])
+	if err != nil || result == rules.Ongoing {
+		return fmt.Errorf("bad result %q", args[1])
+	}
+	return t.SetResult(n-1, result)
+}
+
+// importRecords enters results from finished games saved as records.
+func importRecords(t *tournament.Tournament, files []string) error {
+	for _, file := range files {
+		f, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		recs, err := rules.ReadRecords(f)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %v", file, err)
+		}
+		for _, rec := range recs {
+			i, err := t.ImportRecord(rec)
+			if err != nil {
+				return fmt.Errorf("%s: %v", file, err)
+			}
+			fmt.Printf("game %d: %s\n", i+1, t.Games[i].Result)
+		}
+	}
+	return nil
+}
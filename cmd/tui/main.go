@@ -0,0 +1,56 @@
+// Command tui plays Baag-Chal in a terminal, e.g. over SSH.
+//
+//	tui                 two players at one terminal
+//	tui -ai tiger       play the goats against the engine
+//	tui -ai goat -level hard
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+
+	"github.com/baag_chal_gl/engine"
+	"github.com/baag_chal_gl/rules"
+	"github.com/baag_chal_gl/tui"
+)
+
+func main() {
+	var (
+		aiSide   = flag.String("ai", "", "side played by the engine: goat or tiger")
+		level    = flag.String("level", "medium", "engine preset or settings, or cmd:<command>")
+		position = flag.String("position", "", "start from this position string")
+		noColor  = flag.Bool("nocolor", false, "disable ANSI colours")
+	)
+	flag.Parse()
+
+	opts := tui.Options{Start: rules.NewPosition(), Color: !*noColor}
+	if *position != "" {
+		p, err := rules.ParsePosition(*position)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		opts.Start = p
+	}
+	switch *aiSide {
+	case "":
+	case "goat", "goats":
+		opts.EngineSide = rules.Goat
+	case "tiger", "tigers":
+		opts.EngineSide = rules.Tiger
+	default:
+		log.Fatalf("-ai must be goat or tiger, not %q", *aiSide)
+	}
+	if *aiSide != "" {
+		e, err := engine.Open(*level)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		defer e.Close()
+		opts.Engine = e
+	}
+
+	if err := tui.Run(os.Stdin, os.Stdout, opts); err != nil {
+		log.Fatalln(err)
+	}
+}
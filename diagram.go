@@ -0,0 +1,37 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/baag_chal_gl/diagram"
+)
+
+// runRender is the render command: it draws a position string, or
+// "start", to a PNG file. The drawing is done by the diagram package,
+// which cmd/render uses without the game's cgo dependencies.
+//
+//	baag_chal_gl render -o puzzle.png -coords -arrow c4-c3 "T3T/5/2G2/5/T3T t 1 0"
+func runRender(args []string) int {
+	fs := newFlagSet("render", "position|start", "theme", "assets")
+	var cmd diagram.RenderCommand
+	cmd.SetFlags(fs)
+	fs.Parse(args)
+	if fs.NArg() != 1 || cmd.Size < 16 {
+		fs.Usage()
+		return 2
+	}
+	// Keep the log quiet unless something goes wrong
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+	loadSettings(fs)
+	initAssets()
+
+	if err := cmd.Run(assetFS, *themeFlag, fs.Arg(0)); err != nil {
+		fmt.Fprintln(os.Stderr, "render:", err)
+		return 1
+	}
+	return 0
+}
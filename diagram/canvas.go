@@ -0,0 +1,159 @@
+package diagram
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+
+	"github.com/baag_chal_gl/assets"
+	"github.com/golang/freetype/truetype"
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+	"golang.org/x/image/vector"
+)
+
+// canvas draws NDC shapes onto an image, like gfx does on screen.
+type canvas struct {
+	img  *image.RGBA
+	size float32
+	r    *vector.Rasterizer
+}
+
+func newCanvas(size int) *canvas {
+	return &canvas{
+		img:  image.NewRGBA(image.Rect(0, 0, size, size)),
+		size: float32(size),
+		r:    vector.NewRasterizer(size, size),
+	}
+}
+
+// px converts NDC to pixels.
+func (c *canvas) px(x, y float32) (float32, float32) {
+	return (x + 1) / 2 * c.size, (1 - y) / 2 * c.size
+}
+
+// fill draws the anti-aliased polygons through each of contours, given in
+// pixels. A contour wound the other way cuts a hole.
+func (c *canvas) fill(col assets.Color, contours ...[][2]float32) {
+	// Rasterize only the shape's bounding box
+	minX, minY := float32(math.Inf(1)), float32(math.Inf(1))
+	maxX, maxY := float32(math.Inf(-1)), float32(math.Inf(-1))
+	for _, pts := range contours {
+		for _, p := range pts {
+			minX, minY = min(minX, p[0]), min(minY, p[1])
+			maxX, maxY = max(maxX, p[0]), max(maxY, p[1])
+		}
+	}
+	r := image.Rect(int(math.Floor(float64(minX))), int(math.Floor(float64(minY))),
+		int(math.Ceil(float64(maxX))), int(math.Ceil(float64(maxY)))).Intersect(c.img.Bounds())
+	if r.Empty() {
+		return
+	}
+	ox, oy := float32(r.Min.X), float32(r.Min.Y)
+	c.r.Reset(r.Dx(), r.Dy())
+	c.r.DrawOp = draw.Over
+	for _, pts := range contours {
+		c.r.MoveTo(pts[0][0]-ox, pts[0][1]-oy)
+		for _, p := range pts[1:] {
+			c.r.LineTo(p[0]-ox, p[1]-oy)
+		}
+		c.r.ClosePath()
+	}
+	c.r.Draw(c.img, r, image.NewUniform(nrgba(col)), image.Point{})
+}
+
+// line draws a segment width pixels thick with square caps.
+func (c *canvas) line(x1, y1, x2, y2, width float32, col assets.Color) {
+	ax, ay := c.px(x1, y1)
+	bx, by := c.px(x2, y2)
+	dx, dy := bx-ax, by-ay
+	length := float32(math.Hypot(float64(dx), float64(dy)))
+	if length == 0 {
+		return
+	}
+	half := width / 2
+	ux, uy := dx/length*half, dy/length*half
+	c.fill(col, [][2]float32{
+		{ax - ux - uy, ay - uy + ux},
+		{bx + ux - uy, by + uy + ux},
+		{bx + ux + uy, by + uy - ux},
+		{ax - ux + uy, ay - uy - ux},
+	})
+}
+
+// circlePoints returns a polygon approximating a circle in pixels.
+func (c *canvas) circlePoints(x, y, radius float32) [][2]float32 {
+	const segments = 48
+	cx, cy := c.px(x, y)
+	r := radius / 2 * c.size
+	pts := make([][2]float32, segments)
+	for i := range pts {
+		theta := 2 * math.Pi * float64(i) / segments
+		pts[i] = [2]float32{cx + r*float32(math.Cos(theta)), cy + r*float32(math.Sin(theta))}
+	}
+	return pts
+}
+
+// circle fills a circle of the given radius in NDC.
+func (c *canvas) circle(x, y, radius float32, col assets.Color) {
+	c.fill(col, c.circlePoints(x, y, radius))
+}
+
+// ring draws a circle outline of the given NDC thickness.
+func (c *canvas) ring(x, y, radius, thickness float32, col assets.Color) {
+	inner := c.circlePoints(x, y, radius-thickness)
+	for i, j := 0, len(inner)-1; i < j; i, j = i+1, j-1 {
+		inner[i], inner[j] = inner[j], inner[i]
+	}
+	c.fill(col, c.circlePoints(x, y, radius), inner)
+}
+
+// arrow draws an arrow between two points in NDC, stopping short of the
+// destination so the head sits beside the piece there.
+func (c *canvas) arrow(from, to [2]float32, col assets.Color) {
+	ax, ay := c.px(from[0], from[1])
+	bx, by := c.px(to[0], to[1])
+	dx, dy := bx-ax, by-ay
+	length := float32(math.Hypot(float64(dx), float64(dy)))
+	if length == 0 {
+		return
+	}
+	ux, uy := dx/length, dy/length
+	scale := c.size / 2
+	shaft, head, headW := 0.025*scale, 0.09*scale, 0.06*scale
+	// Tip just inside the destination's piece
+	tipX, tipY := bx-ux*0.04*scale, by-uy*0.04*scale
+	baseX, baseY := tipX-ux*head, tipY-uy*head
+	nx, ny := -uy, ux
+	c.fill(col, [][2]float32{
+		{ax + nx*shaft/2, ay + ny*shaft/2},
+		{baseX + nx*shaft/2, baseY + ny*shaft/2},
+		{baseX + nx*headW, baseY + ny*headW},
+		{tipX, tipY},
+		{baseX - nx*headW, baseY - ny*headW},
+		{baseX - nx*shaft/2, baseY - ny*shaft/2},
+		{ax - nx*shaft/2, ay - ny*shaft/2},
+	})
+}
+
+// text draws a line of text centred on (x, y) in NDC.
+func (c *canvas) text(f *truetype.Font, size float64, x, y float32, s string, col assets.Color) {
+	face := truetype.NewFace(f, &truetype.Options{Size: size, DPI: 72, Hinting: font.HintingFull})
+	defer face.Close()
+	d := font.Drawer{Dst: c.img, Src: image.NewUniform(nrgba(col)), Face: face}
+	px, py := c.px(x, y)
+	m := face.Metrics()
+	width := d.MeasureString(s)
+	d.Dot = fixed.Point26_6{
+		X: fixed.Int26_6(px*64) - width/2,
+		Y: fixed.Int26_6(py*64) + (m.Ascent-m.Descent)/2,
+	}
+	d.DrawString(s)
+}
+
+// nrgba converts a theme colour to an image colour.
+func nrgba(c assets.Color) color.NRGBA {
+	b := func(v float32) uint8 { return uint8(min(max(v, 0), 1)*255 + 0.5) }
+	return color.NRGBA{b(c[0]), b(c[1]), b(c[2]), b(c[3])}
+}
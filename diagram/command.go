@@ -0,0 +1,168 @@
+package diagram
+
+import (
+	"flag"
+	"fmt"
+	"image/png"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/baag_chal_gl/assets"
+	"github.com/baag_chal_gl/rules"
+)
+
+// RenderCommand holds the flags of the render command, which the game and
+// cmd/render share. The theme and asset flags are left to the caller.
+type RenderCommand struct {
+	Out     string
+	Size    int
+	Coords  bool
+	Caption string
+	// Arrows and Highlights are moves and points as typed, such as c3-c4
+	// and c3.
+	Arrows, Highlights []string
+}
+
+// SetFlags defines the command's flags in fs.
+func (c *RenderCommand) SetFlags(fs *flag.FlagSet) {
+	fs.StringVar(&c.Out, "o", "board.png", "output file, or - for standard output")
+	fs.IntVar(&c.Size, "size", 800, "image width and height in pixels")
+	fs.BoolVar(&c.Coords, "coords", false, "label the files and ranks")
+	fs.StringVar(&c.Caption, "caption", "", "text above the board")
+	fs.Var((*listFlag)(&c.Arrows), "arrow", "move to draw as an arrow, such as c3-c4; repeatable or comma separated")
+	fs.Var((*listFlag)(&c.Highlights), "highlight", "point to highlight, such as c3; repeatable or comma separated")
+}
+
+// Run draws a position string, or "start", to the output file using the
+// theme with the given ID from fsys.
+func (c *RenderCommand) Run(fsys fs.FS, themeID, pos string) error {
+	p := rules.NewPosition()
+	if pos != "start" {
+		var err error
+		if p, err = rules.ParsePosition(pos); err != nil {
+			return err
+		}
+	}
+	opt := Options{Size: c.Size, Coords: c.Coords, Caption: c.Caption}
+	for _, s := range c.Arrows {
+		m, err := rules.ParseMove(s)
+		if err != nil {
+			return err
+		}
+		opt.Arrows = append(opt.Arrows, m)
+	}
+	for _, s := range c.Highlights {
+		pt, err := rules.ParsePoint(s)
+		if err != nil {
+			return err
+		}
+		opt.Highlights = append(opt.Highlights, pt)
+	}
+	var err error
+	if opt.Theme, err = readTheme(fsys, themeID); err != nil {
+		return err
+	}
+
+	img, err := NewRenderer(fsys).Render(p, opt)
+	if err != nil {
+		return err
+	}
+	if c.Out == "-" {
+		return png.Encode(os.Stdout, img)
+	}
+	f, err := os.Create(c.Out)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(f, img); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// ReplayCommand holds the flags of the replay command, which the game and
+// cmd/replay share. The theme and asset flags are left to the caller.
+type ReplayCommand struct {
+	Out    string
+	Format string
+	Size   int
+	Delay  time.Duration
+	Coords bool
+}
+
+// SetFlags defines the command's flags in fs.
+func (c *ReplayCommand) SetFlags(fs *flag.FlagSet) {
+	fs.StringVar(&c.Out, "o", "replay.gif", "output file; .png or .apng writes an APNG, anything else a GIF")
+	fs.StringVar(&c.Format, "format", "", "gif or apng (default: from the output file name)")
+	fs.IntVar(&c.Size, "size", 480, "image width and height in pixels")
+	fs.DurationVar(&c.Delay, "delay", time.Second, "time each move is shown")
+	fs.BoolVar(&c.Coords, "coords", false, "label the files and ranks")
+}
+
+// Run turns the game record in the named file, or standard input for "-",
+// into an animation using the theme with the given ID from fsys.
+func (c *ReplayCommand) Run(fsys fs.FS, themeID, in string) error {
+	var data []byte
+	var err error
+	if in == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(in)
+	}
+	if err != nil {
+		return err
+	}
+	rec, err := rules.ParseRecord(string(data))
+	if err != nil {
+		return err
+	}
+	opt := ReplayOptions{Size: c.Size, Delay: c.Delay, Coords: c.Coords}
+	if opt.Theme, err = readTheme(fsys, themeID); err != nil {
+		return err
+	}
+	format := c.Format
+	if format == "" {
+		format = ReplayFormat(c.Out)
+	}
+
+	frames, err := NewRenderer(fsys).Replay(rec, opt)
+	if err != nil {
+		return err
+	}
+	f, err := os.Create(c.Out)
+	if err != nil {
+		return err
+	}
+	if err := WriteReplay(f, format, frames); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func readTheme(fsys fs.FS, id string) (*assets.Theme, error) {
+	t, err := assets.ReadTheme(fsys, id)
+	if err != nil {
+		return nil, fmt.Errorf("unknown theme %q: %v", id, err)
+	}
+	return t, nil
+}
+
+// listFlag collects a flag given several times or as a comma separated
+// list.
+type listFlag []string
+
+func (l *listFlag) String() string { return strings.Join(*l, ",") }
+
+func (l *listFlag) Set(s string) error {
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			*l = append(*l, v)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,241 @@
+// Package diagram draws board diagrams in pure Go, without OpenGL or a
+// window, for docs, puzzles and bug reports. It uses the same layout and
+// theme art as the game: the board fills the middle of a square image.
+package diagram
+
+import (
+	"image"
+	"image/draw"
+	"image/png"
+	"io/fs"
+	"math"
+	"path"
+	"sync"
+
+	"github.com/baag_chal_gl/assets"
+	"github.com/baag_chal_gl/rules"
+	"github.com/golang/freetype/truetype"
+	xdraw "golang.org/x/image/draw"
+)
+
+// Options controls Render.
+type Options struct {
+	// Size is the width and height in pixels.
+	Size  int
+	Theme *assets.Theme
+	// Coords labels the files a-e and ranks 1-5.
+	Coords bool
+	// Arrows are drawn from each move's origin to its destination.
+	Arrows []rules.Move
+	// Highlights are points marked under the pieces.
+	Highlights [][2]int
+	// Captures marks points where a goat was just captured.
+	Captures [][2]int
+	// Caption is a line of text above the board.
+	Caption string
+}
+
+// A Renderer draws diagrams with art and fonts from an asset filesystem.
+// It caches decoded art, or nil for missing files, the art scaled to each
+// size drawn, and parsed fonts, so replays do not redo them for every
+// frame. It is safe for concurrent use.
+type Renderer struct {
+	fsys fs.FS
+
+	mu     sync.Mutex
+	images map[string]image.Image
+	scaled map[scaledKey]*image.RGBA
+	fonts  map[string]*truetype.Font
+}
+
+type scaledKey struct {
+	img  image.Image
+	w, h int
+}
+
+// NewRenderer returns a renderer reading assets from fsys.
+func NewRenderer(fsys fs.FS) *Renderer {
+	return &Renderer{
+		fsys:   fsys,
+		images: map[string]image.Image{},
+		scaled: map[scaledKey]*image.RGBA{},
+		fonts:  map[string]*truetype.Font{},
+	}
+}
+
+// BoardLines lists the board's lines in NDC as x1, y1, x2, y2.
+func BoardLines() [][4]float32 {
+	var lines [][4]float32
+	// 5 vertical + 5 horizontal lines
+	for i := 0; i < rules.Size; i++ {
+		v := float32(-0.8 + 0.4*float32(i))
+		lines = append(lines, [4]float32{-0.8, v, 0.8, v}, [4]float32{v, -0.8, v, 0.8})
+	}
+
+	// Some diagonals (traditional Baag-Chal has specific diagonals):
+	return append(lines,
+		// Main diagonal
+		[4]float32{-0.8, 0.8, 0.8, -0.8},
+		// Opposite diagonal
+		[4]float32{-0.8, -0.8, 0.8, 0.8},
+		// Extra diagonals to center points
+		[4]float32{0.0, 0.8, -0.8, 0.0},
+		[4]float32{0.0, 0.8, 0.8, 0.0},
+		[4]float32{0.0, -0.8, -0.8, 0.0},
+		[4]float32{0.0, -0.8, 0.8, 0.0},
+	)
+}
+
+// gridPoint is the NDC centre of a point of the grid.
+func gridPoint(p [2]int) [2]float32 {
+	return [2]float32{-0.8 + 0.4*float32(p[0]), -0.8 + 0.4*float32(p[1])}
+}
+
+// Render draws p as an image.
+func (r *Renderer) Render(p rules.Position, opt Options) (*image.RGBA, error) {
+	t := opt.Theme
+	if t == nil {
+		t = &assets.DefaultTheme
+	}
+	c := newCanvas(opt.Size)
+	draw.Draw(c.img, c.img.Bounds(), image.NewUniform(nrgba(t.BackgroundColor)), image.Point{}, draw.Src)
+	if t.Background != "" {
+		if bg := r.image(path.Join(t.Dir, t.Background)); bg != nil {
+			r.picture(c, bg, 0, 0, 2)
+		}
+	}
+
+	// Lines are as thick as in a 1000 pixel window
+	width := max(t.LineWidth*c.size/1000, 1)
+	for _, l := range BoardLines() {
+		c.line(l[0], l[1], l[2], l[3], width, t.LineColor)
+	}
+	for _, pt := range opt.Highlights {
+		b := gridPoint(pt)
+		c.circle(b[0], b[1], 0.075, t.Highlight)
+	}
+	for _, pt := range opt.Captures {
+		b := gridPoint(pt)
+		c.circle(b[0], b[1], 0.09, t.Threat)
+	}
+
+	f, err := r.font(path.Join(t.Dir, t.Font))
+	if err != nil {
+		if f, err = r.font(assets.DefaultFont); err != nil {
+			return nil, err
+		}
+	}
+	textSize := float64(c.size) * 0.035
+
+	goat := r.image(path.Join(t.Dir, t.Goat))
+	tiger := r.image(path.Join(t.Dir, t.Tiger))
+	for x := 0; x < rules.Size; x++ {
+		for y := 0; y < rules.Size; y++ {
+			b := gridPoint([2]int{x, y})
+			switch p.Board[x][y] {
+			case rules.Goat:
+				r.piece(c, goat, b, 0.12, assets.Color{0.85, 0.85, 0.8, 1}, "G", f)
+			case rules.Tiger:
+				r.piece(c, tiger, b, 0.15, assets.Color{0.95, 0.55, 0.1, 1}, "T", f)
+			}
+		}
+	}
+
+	arrow := t.LastMove
+	arrow[3] = 0.85
+	for _, m := range opt.Arrows {
+		if m.IsPlacement() {
+			b := gridPoint(m.To)
+			c.ring(b[0], b[1], 0.09, 0.02, arrow)
+			continue
+		}
+		c.arrow(gridPoint(m.From), gridPoint(m.To), arrow)
+	}
+
+	if opt.Coords {
+		for i := 0; i < rules.Size; i++ {
+			v := -0.8 + 0.4*float32(i)
+			c.text(f, textSize, v, -0.92, string(rune('a'+i)), t.TextColor)
+			c.text(f, textSize, -0.92, v, string(rune('1'+i)), t.TextColor)
+		}
+	}
+	if opt.Caption != "" {
+		c.text(f, textSize, 0, 0.9, opt.Caption, t.TextColor)
+	}
+	return c.img, nil
+}
+
+// picture draws img scaled to a square of side size in NDC centred on
+// (x, y).
+func (r *Renderer) picture(c *canvas, img image.Image, x, y, size float32) {
+	x1, y1 := c.px(x-size/2, y+size/2)
+	x2, y2 := c.px(x+size/2, y-size/2)
+	rect := image.Rect(int(x1), int(y1), int(math.Ceil(float64(x2))), int(math.Ceil(float64(y2))))
+	draw.Draw(c.img, rect, r.scale(img, rect.Dx(), rect.Dy()), image.Point{}, draw.Over)
+}
+
+// piece draws piece art, or an outlined disc with the piece's initial
+// when the theme has none, as the game does.
+func (r *Renderer) piece(c *canvas, art image.Image, at [2]float32, size float32, base assets.Color, label string, f *truetype.Font) {
+	if art != nil {
+		r.picture(c, art, at[0], at[1], size)
+		return
+	}
+	c.circle(at[0], at[1], size*0.5, assets.Color{0.1, 0.1, 0.1, 1})
+	c.circle(at[0], at[1], size*0.42, base)
+	c.text(f, float64(size*c.size)*0.25, at[0], at[1], label, assets.Color{0.1, 0.1, 0.1, 1})
+}
+
+// image returns the decoded PNG file, or nil if it is missing or broken.
+func (r *Renderer) image(file string) image.Image {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	img, ok := r.images[file]
+	if !ok {
+		img, _ = r.decode(file)
+		r.images[file] = img
+	}
+	return img
+}
+
+func (r *Renderer) decode(file string) (image.Image, error) {
+	f, err := r.fsys.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return png.Decode(f)
+}
+
+// scale returns img resized to w by h pixels.
+func (r *Renderer) scale(img image.Image, w, h int) *image.RGBA {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	k := scaledKey{img, w, h}
+	s, ok := r.scaled[k]
+	if !ok {
+		s = image.NewRGBA(image.Rect(0, 0, w, h))
+		xdraw.CatmullRom.Scale(s, s.Rect, img, img.Bounds(), draw.Src, nil)
+		r.scaled[k] = s
+	}
+	return s
+}
+
+// font parses a font file, once per path.
+func (r *Renderer) font(file string) (*truetype.Font, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if f, ok := r.fonts[file]; ok {
+		return f, nil
+	}
+	data, err := fs.ReadFile(r.fsys, file)
+	if err != nil {
+		return nil, err
+	}
+	f, err := truetype.Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	r.fonts[file] = f
+	return f, nil
+}
@@ -0,0 +1,282 @@
+package diagram
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"image"
+	"image/color"
+	colorpalette "image/color/palette"
+	"image/gif"
+	"image/png"
+	"io"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/baag_chal_gl/assets"
+	"github.com/baag_chal_gl/rules"
+)
+
+// Replays are animated GIF or APNG files of a whole game, one frame per
+// position drawn by Render. Each frame has the move as its caption, an
+// arrow for the move and a mark where a goat was captured.
+
+// ReplayOptions controls Replay.
+type ReplayOptions struct {
+	Size  int
+	Theme *assets.Theme
+	// Delay is how long each move is shown; captures and the final
+	// position are held longer.
+	Delay  time.Duration
+	Coords bool
+}
+
+// Frame is one image of a replay and how long it is shown.
+type Frame struct {
+	Image *image.RGBA
+	Delay time.Duration
+}
+
+// Replay draws the start position and the position after every move of
+// rec.
+func (r *Renderer) Replay(rec *rules.Record, opt ReplayOptions) ([]Frame, error) {
+	g := rec.Game
+	caption := "Start"
+	if goat, tiger := rec.Tags["Goat"], rec.Tags["Tiger"]; goat != "" && tiger != "" {
+		caption = goat + " vs " + tiger
+	}
+	frames := make([]Frame, 0, g.Ply()+1)
+	add := func(d Options, p rules.Position, delay time.Duration) error {
+		d.Size, d.Theme, d.Coords = opt.Size, opt.Theme, opt.Coords
+		img, err := r.Render(p, d)
+		if err != nil {
+			return err
+		}
+		frames = append(frames, Frame{img, delay})
+		return nil
+	}
+	if err := add(Options{Caption: caption}, g.Start, opt.Delay); err != nil {
+		return nil, err
+	}
+
+	for i, m := range g.Moves {
+		d := Options{Arrows: []rules.Move{m}, Caption: replayCaption(g, i)}
+		delay := opt.Delay
+		if m.IsJump() {
+			d.Captures = [][2]int{m.Captured()}
+			delay = opt.Delay * 3 / 2
+		}
+		if i == g.Ply()-1 {
+			if r := rec.Result(); r != rules.Ongoing {
+				d.Caption += "  " + resultText(r)
+			}
+			delay = opt.Delay * 3
+		}
+		if err := add(d, g.PositionAt(i+1), delay); err != nil {
+			return nil, err
+		}
+	}
+	return frames, nil
+}
+
+// replayCaption numbers move i like the game record does: "3. c3" for a
+// goat move and "3... a1-b1" for a tiger move.
+func replayCaption(g *rules.Game, i int) string {
+	dots := "."
+	if g.PositionAt(i).Turn == rules.Tiger {
+		dots = "..."
+	}
+	return fmt.Sprintf("%d%s %s", g.MoveNumber(i), dots, g.Moves[i])
+}
+
+func resultText(r rules.Result) string {
+	switch r {
+	case rules.GoatsWin:
+		return "Goats win"
+	case rules.TigersWin:
+		return "Tigers win"
+	}
+	return "Draw"
+}
+
+// WriteReplay encodes frames as "gif" or "apng". Both loop forever.
+func WriteReplay(w io.Writer, format string, frames []Frame) error {
+	if len(frames) == 0 {
+		return errors.New("no frames")
+	}
+	switch format {
+	case "gif":
+		return encodeGIF(w, frames)
+	case "apng":
+		return encodeAPNG(w, frames)
+	}
+	return fmt.Errorf("unknown replay format %q", format)
+}
+
+// ReplayFormat picks the format from a file name: APNG for .png and
+// .apng, otherwise GIF.
+func ReplayFormat(file string) string {
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".png", ".apng":
+		return "apng"
+	}
+	return "gif"
+}
+
+func encodeGIF(w io.Writer, frames []Frame) error {
+	anim := &gif.GIF{}
+	for _, f := range frames {
+		anim.Image = append(anim.Image, ditherPlan9(f.Image))
+		// GIF delays are in hundredths of a second
+		anim.Delay = append(anim.Delay, int(f.Delay/(10*time.Millisecond)))
+	}
+	return gif.EncodeAll(w, anim)
+}
+
+// plan9Lookup maps a colour with 5 bits per channel to the nearest entry
+// of the Plan 9 palette. image/draw searches the whole palette for every
+// pixel, which makes long replays take many seconds.
+var plan9Lookup = sync.OnceValue(func() []uint8 {
+	t := make([]uint8, 1<<15)
+	p := color.Palette(colorpalette.Plan9)
+	for i := range t {
+		r, g, b := uint8(i>>10)<<3|4, uint8(i>>5&31)<<3|4, uint8(i&31)<<3|4
+		t[i] = uint8(p.Index(color.RGBA{r, g, b, 255}))
+	}
+	return t
+})
+
+// ditherPlan9 converts img to the Plan 9 palette with Floyd-Steinberg
+// error diffusion.
+func ditherPlan9(img *image.RGBA) *image.Paletted {
+	lookup := plan9Lookup()
+	b := img.Bounds()
+	out := image.NewPaletted(b, colorpalette.Plan9)
+	w := b.Dx()
+	// Errors carried to the current and next row, three channels per pixel
+	// with a pixel of padding on each side
+	cur, next := make([]int32, 3*(w+2)), make([]int32, 3*(w+2))
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := 0; x < w; x++ {
+			o := img.PixOffset(b.Min.X+x, y)
+			var c [3]int32
+			for k := range c {
+				c[k] = min(max(int32(img.Pix[o+k])+cur[3*(x+1)+k]/16, 0), 255)
+			}
+			i := lookup[c[0]>>3<<10|c[1]>>3<<5|c[2]>>3]
+			out.Pix[out.PixOffset(b.Min.X+x, y)] = i
+			r, g, bl, _ := colorpalette.Plan9[i].RGBA()
+			got := [3]int32{int32(r >> 8), int32(g >> 8), int32(bl >> 8)}
+			for k := range c {
+				e := c[k] - got[k]
+				cur[3*(x+2)+k] += e * 7
+				next[3*x+k] += e * 3
+				next[3*(x+1)+k] += e * 5
+				next[3*(x+2)+k] += e
+			}
+		}
+		cur, next = next, cur
+		clear(next)
+	}
+	return out
+}
+
+// encodeAPNG writes an animated PNG. Each frame is encoded as a PNG and
+// its image data moved into the animation chunks: the first frame's IDAT
+// doubles as the default image, later frames become fdAT chunks.
+func encodeAPNG(w io.Writer, frames []Frame) error {
+	var out bytes.Buffer
+	out.WriteString("\x89PNG\r\n\x1a\n")
+	seq := uint32(0)
+	var header []byte
+	for i, f := range frames {
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, f.Image); err != nil {
+			return err
+		}
+		chunks, err := pngChunks(buf.Bytes())
+		if err != nil {
+			return err
+		}
+		if i == 0 {
+			header = chunks[0].data
+			writePNGChunk(&out, "IHDR", header)
+			actl := make([]byte, 8)
+			binary.BigEndian.PutUint32(actl, uint32(len(frames)))
+			writePNGChunk(&out, "acTL", actl)
+		} else if !bytes.Equal(chunks[0].data, header) {
+			return errors.New("replay frames differ in size or colour type")
+		}
+
+		b := f.Image.Bounds()
+		fctl := make([]byte, 26)
+		binary.BigEndian.PutUint32(fctl[0:], seq)
+		binary.BigEndian.PutUint32(fctl[4:], uint32(b.Dx()))
+		binary.BigEndian.PutUint32(fctl[8:], uint32(b.Dy()))
+		// Offsets stay zero; the delay is in milliseconds
+		binary.BigEndian.PutUint16(fctl[20:], uint16(min(f.Delay.Milliseconds(), 65535)))
+		binary.BigEndian.PutUint16(fctl[22:], 1000)
+		writePNGChunk(&out, "fcTL", fctl)
+		seq++
+
+		for _, c := range chunks {
+			if c.kind != "IDAT" {
+				continue
+			}
+			if i == 0 {
+				writePNGChunk(&out, "IDAT", c.data)
+				continue
+			}
+			fdat := binary.BigEndian.AppendUint32(nil, seq)
+			writePNGChunk(&out, "fdAT", append(fdat, c.data...))
+			seq++
+		}
+	}
+	writePNGChunk(&out, "IEND", nil)
+	_, err := w.Write(out.Bytes())
+	return err
+}
+
+type pngChunk struct {
+	kind string
+	data []byte
+}
+
+// pngChunks splits an encoded PNG into its chunks, IHDR first.
+func pngChunks(b []byte) ([]pngChunk, error) {
+	const sigLen = 8
+	if len(b) < sigLen {
+		return nil, errors.New("short PNG")
+	}
+	b = b[sigLen:]
+	var chunks []pngChunk
+	for len(b) >= 12 {
+		n := int(binary.BigEndian.Uint32(b))
+		if 12+n > len(b) {
+			return nil, errors.New("truncated PNG chunk")
+		}
+		chunks = append(chunks, pngChunk{string(b[4:8]), b[8 : 8+n]})
+		b = b[12+n:]
+	}
+	if len(chunks) == 0 || chunks[0].kind != "IHDR" {
+		return nil, errors.New("PNG has no header")
+	}
+	return chunks, nil
+}
+
+func writePNGChunk(w *bytes.Buffer, kind string, data []byte) {
+	var n [4]byte
+	binary.BigEndian.PutUint32(n[:], uint32(len(data)))
+	w.Write(n[:])
+	crc := crc32.NewIEEE()
+	crc.Write([]byte(kind))
+	crc.Write(data)
+	w.WriteString(kind)
+	w.Write(data)
+	binary.BigEndian.PutUint32(n[:], crc.Sum32())
+	w.Write(n[:])
+}
@@ -0,0 +1,279 @@
+package main
+
+import (
+	"log"
+	"strings"
+
+	"github.com/go-gl/glfw/v3.3/glfw"
+)
+
+// dialogButton is one button of a modal dialog.
+type dialogButton struct {
+	Label   string
+	Color   [4]float32
+	OnClick func()
+	// Default is activated by Enter, Cancel by Escape.
+	Default bool
+	Cancel  bool
+}
+
+// dialog is a modal box with an optional icon, a title, a message that is
+// wrapped to fit and a row of buttons. Dialogs stack: only the top one
+// takes input, and closing it reveals the one below.
+type dialog struct {
+	Title   string
+	Message string
+	// Icon is the asset path of an icon image. IconPiece draws a goat or
+	// tiger in the current theme instead, or as a fallback.
+	Icon      string
+	IconPiece int
+	Buttons   []dialogButton
+
+	focus int
+}
+
+var dialogs []*dialog
+
+var (
+	okColor     = [4]float32{0.4, 0.8, 0.4, 1}
+	cancelColor = [4]float32{0.8, 0.4, 0.4, 1}
+	plainColor  = [4]float32{0.65, 0.65, 0.65, 1}
+)
+
+// Dialog geometry in NDC.
+const (
+	dialogWidth    = 1.1
+	dialogPad      = 0.05
+	dialogIconSize = 0.16
+	dialogButtonH  = 0.1
+	dialogButtonW  = 0.24
+	dialogGap      = 0.04
+	// dialogStackOffset shifts each stacked dialog so the ones below show
+	dialogStackOffset = 0.04
+)
+
+// pushDialog opens d on top of any open dialogs.
+func pushDialog(d *dialog) {
+	d.focus = 0
+	for i, b := range d.Buttons {
+		if b.Default {
+			d.focus = i
+		}
+	}
+	dialogs = append(dialogs, d)
+	log.Printf("[DIALOG] Title: %s\nMessage: %s", d.Title, d.Message)
+}
+
+// dialogOpen reports whether a modal dialog is showing.
+func dialogOpen() bool {
+	return len(dialogs) > 0
+}
+
+func topDialog() *dialog {
+	if len(dialogs) == 0 {
+		return nil
+	}
+	return dialogs[len(dialogs)-1]
+}
+
+// closeDialog removes d from the stack.
+func closeDialog(d *dialog) {
+	for i, o := range dialogs {
+		if o == d {
+			dialogs = append(dialogs[:i], dialogs[i+1:]...)
+			return
+		}
+	}
+}
+
+// activate closes d and runs the callback of button i. The dialog is closed
+// first so the callback can open another one.
+func (d *dialog) activate(i int) {
+	if i < 0 || i >= len(d.Buttons) {
+		return
+	}
+	closeDialog(d)
+	if fn := d.Buttons[i].OnClick; fn != nil {
+		fn()
+	}
+}
+
+// dialogLayout is the placement of one dialog's parts in NDC.
+type dialogLayout struct {
+	box     uiRect
+	icon    uiRect
+	hasIcon bool
+	titleY  float32
+	message []string
+	msgY    float32
+	buttons []uiRect
+}
+
+// layout sizes d around its wrapped text; depth is its index in the stack.
+func (d *dialog) layout(depth int) dialogLayout {
+	var l dialogLayout
+	inner := float32(dialogWidth - 2*dialogPad)
+	l.hasIcon = d.Icon != "" || d.IconPiece != 0
+	_, titleH := measureText(d.Title, titleTextSize)
+	l.message = wrapText(d.Message, uiTextSize, inner)
+	_, lineH := measureText("M", uiTextSize)
+	msgH := lineH * float32(len(l.message))
+
+	h := float32(dialogPad) + titleH + dialogGap + msgH + dialogGap + dialogButtonH + dialogPad
+	if l.hasIcon {
+		h += dialogIconSize + dialogGap
+	}
+	offset := float32(depth) * dialogStackOffset
+	top := h/2 - offset
+	l.box = uiRect{-dialogWidth/2 + offset, top, dialogWidth/2 + offset, top - h}
+
+	y := top - dialogPad
+	cx := (l.box.x1 + l.box.x2) / 2
+	if l.hasIcon {
+		l.icon = uiRect{cx - dialogIconSize/2, y, cx + dialogIconSize/2, y - dialogIconSize}
+		y -= dialogIconSize + dialogGap
+	}
+	l.titleY = y
+	y -= titleH + dialogGap
+	l.msgY = y
+
+	// Buttons are centred in a row along the bottom edge.
+	widths := make([]float32, len(d.Buttons))
+	total := float32(0)
+	for i, b := range d.Buttons {
+		w, _ := measureText(b.Label, uiTextSize)
+		widths[i] = w + 0.08
+		if widths[i] < dialogButtonW {
+			widths[i] = dialogButtonW
+		}
+		total += widths[i]
+	}
+	total += dialogGap * float32(len(d.Buttons)-1)
+	bx := cx - total/2
+	by := l.box.y2 + dialogPad + dialogButtonH
+	for _, w := range widths {
+		l.buttons = append(l.buttons, uiRect{bx, by, bx + w, by - dialogButtonH})
+		bx += w + dialogGap
+	}
+	return l
+}
+
+// drawDialogs draws the whole stack, each dialog dimming what is below.
+func drawDialogs() {
+	dark := [4]float32{0.1, 0.1, 0.1, 1}
+	for depth, d := range dialogs {
+		l := d.layout(depth)
+		gfx.rect(-1.0, 1.0, 1.0, -1.0, [4]float32{0.0, 0.0, 0.0, 0.5})
+		gfx.rect(l.box.x1, l.box.y1, l.box.x2, l.box.y2, [4]float32{0.8, 0.8, 0.8, 1})
+
+		if l.hasIcon {
+			d.drawIcon(l.icon)
+		}
+		cx := (l.box.x1 + l.box.x2) / 2
+		drawText(cx, l.titleY, d.Title, textOptions{size: titleTextSize, align: alignCenter, color: dark})
+		if len(l.message) > 0 {
+			drawText(cx, l.msgY, strings.Join(l.message, "\n"), textOptions{size: uiTextSize, align: alignCenter, color: dark})
+		}
+
+		top := depth == len(dialogs)-1
+		for i, b := range d.Buttons {
+			r := l.buttons[i]
+			if top && i == d.focus {
+				// Focus ring for keyboard navigation
+				gfx.rect(r.x1-0.01, r.y1+0.01, r.x2+0.01, r.y2-0.01, dark)
+			}
+			c := b.Color
+			if c == [4]float32{} {
+				c = plainColor
+			}
+			gfx.rect(r.x1, r.y1, r.x2, r.y2, c)
+			drawButtonLabel(r.x1, r.y1, r.x2, r.y2, b.Label, dark)
+		}
+	}
+}
+
+func (d *dialog) drawIcon(r uiRect) {
+	cx, cy := (r.x1+r.x2)/2, (r.y1+r.y2)/2
+	if d.Icon != "" {
+		if tex := assetTexture(d.Icon); tex != 0 {
+			gfx.sprite(tex, r.x1, r.y1, r.x2, r.y2, 0, 0, 1, 1, white)
+			return
+		}
+	}
+	if d.IconPiece != 0 {
+		// Pieces are drawn at 0.12-0.15 NDC; fill the icon box instead
+		drawPiece(d.IconPiece, cx, cy, (r.x2-r.x1)/0.15, white)
+	}
+}
+
+// dialogClick routes a click to the top dialog. Clicks outside its buttons
+// are swallowed so nothing behind a modal reacts.
+func dialogClick(x, y float32) {
+	d := topDialog()
+	l := d.layout(len(dialogs) - 1)
+	for i, r := range l.buttons {
+		if pointInRect(x, y, r) {
+			d.activate(i)
+			return
+		}
+	}
+}
+
+// dialogHover moves keyboard focus to the button under the cursor.
+func dialogHover(x, y float32) {
+	d := topDialog()
+	l := d.layout(len(dialogs) - 1)
+	for i, r := range l.buttons {
+		if pointInRect(x, y, r) {
+			d.focus = i
+		}
+	}
+}
+
+// dialogKey handles keyboard navigation of the top dialog.
+func dialogKey(key glfw.Key, mods glfw.ModifierKey) {
+	d := topDialog()
+	n := len(d.Buttons)
+	switch key {
+	case glfw.KeyEnter, glfw.KeyKPEnter, glfw.KeySpace:
+		d.activate(d.focus)
+	case glfw.KeyEscape:
+		for i, b := range d.Buttons {
+			if b.Cancel {
+				d.activate(i)
+				return
+			}
+		}
+	case glfw.KeyLeft:
+		if n > 0 {
+			d.focus = (d.focus + n - 1) % n
+		}
+	case glfw.KeyRight:
+		if n > 0 {
+			d.focus = (d.focus + 1) % n
+		}
+	case glfw.KeyTab:
+		if n > 0 {
+			if mods&glfw.ModShift != 0 {
+				d.focus = (d.focus + n - 1) % n
+			} else {
+				d.focus = (d.focus + 1) % n
+			}
+		}
+	}
+}
+
+// assetTexture loads an image from the asset filesystem once, remembering
+// failures as 0 so they are not retried every frame.
+func assetTexture(file string) uint32 {
+	if tex, ok := textures[file]; ok {
+		return tex
+	}
+	tex, err := LoadTexture(file)
+	if err != nil {
+		log.Printf("[Assets] %v", err)
+		tex = 0
+	}
+	textures[file] = tex
+	return tex
+}
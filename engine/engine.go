@@ -0,0 +1,84 @@
+// Package engine provides computer players: a built-in alpha-beta searcher
+// and a client for external engines speaking the line protocol in
+// protocol.go.
+package engine
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/baag_chal_gl/rules"
+)
+
+// Engine chooses moves for the side to move in a game.
+type Engine interface {
+	Name() string
+	BestMove(ctx context.Context, g *rules.Game) (rules.Move, error)
+	Close() error
+}
+
+// Presets are the named built-in configurations.
+var Presets = map[string]Config{
+	"random": {Name: "random", Depth: 0},
+	"easy":   {Name: "easy", Depth: 2, Noise: 40},
+	"medium": {Name: "medium", Depth: 4, Noise: 10},
+	"hard":   {Name: "hard", Depth: 6},
+}
+
+// Open creates an engine from a spec. A spec is either "cmd:" followed by a
+// command line starting an external engine, a preset name, or a list of
+// built-in settings such as "depth=5,noise=20".
+func Open(spec string) (Engine, error) {
+	if cmdline, ok := strings.CutPrefix(spec, "cmd:"); ok {
+		e, err := StartExternal(strings.Fields(cmdline)...)
+		if err != nil {
+			return nil, err
+		}
+		return e, nil
+	}
+	cfg, err := ParseConfig(spec)
+	if err != nil {
+		return nil, err
+	}
+	return NewSearcher(cfg), nil
+}
+
+// ParseConfig turns a preset name or "key=value,..." settings into a Config.
+// Settings may follow a preset name to adjust it, as in "hard,noise=5".
+func ParseConfig(spec string) (Config, error) {
+	cfg := Presets["medium"]
+	for i, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			preset, found := Presets[part]
+			if !found || i > 0 {
+				return cfg, fmt.Errorf("unknown engine preset %q", part)
+			}
+			cfg = preset
+			continue
+		}
+		if key == "name" {
+			cfg.Name = value
+			continue
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return cfg, fmt.Errorf("engine setting %q: %v", part, err)
+		}
+		switch key {
+		case "depth":
+			cfg.Depth = n
+		case "noise":
+			cfg.Noise = n
+		case "seed":
+			cfg.Seed = int64(n)
+		default:
+			return cfg, fmt.Errorf("unknown engine setting %q", key)
+		}
+		cfg.Name = spec
+	}
+	return cfg, nil
+}
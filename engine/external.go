@@ -0,0 +1,164 @@
+package engine
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/baag_chal_gl/rules"
+)
+
+// handshakeTimeout bounds how long an external engine may take to start.
+const handshakeTimeout = 10 * time.Second
+
+// External is an engine running in another process, driven over the
+// engine protocol.
+type External struct {
+	name  string
+	cmd   *exec.Cmd
+	stdin io.WriteCloser
+	lines chan string
+	// stale counts searches given up on whose bestmove has yet to arrive
+	stale int
+}
+
+// StartExternal starts the engine command and performs the handshake.
+func StartExternal(args ...string) (*External, error) {
+	if len(args) == 0 {
+		return nil, fmt.Errorf("external engine: empty command")
+	}
+	cmd := exec.Command(args[0], args[1:]...)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	e := &External{name: args[0], cmd: cmd, stdin: stdin, lines: make(chan string, 16)}
+	go func() {
+		sc := bufio.NewScanner(stdout)
+		for sc.Scan() {
+			e.lines <- sc.Text()
+		}
+		close(e.lines)
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), handshakeTimeout)
+	defer cancel()
+	if err := e.send("bcp"); err != nil {
+		e.Close()
+		return nil, err
+	}
+	for {
+		line, err := e.readLine(ctx)
+		if err != nil {
+			e.Close()
+			return nil, fmt.Errorf("external engine %s: handshake: %v", args[0], err)
+		}
+		if name, ok := strings.CutPrefix(line, "id name "); ok {
+			e.name = name
+		}
+		if line == "bcpok" {
+			return e, nil
+		}
+	}
+}
+
+func (e *External) Name() string { return e.name }
+
+// BestMove sends the game and waits for the engine's move. If ctx has a
+// deadline it is passed on as the move time.
+func (e *External) BestMove(ctx context.Context, g *rules.Game) (rules.Move, error) {
+	// Skip the late answers to searches that timed out, so that one is not
+	// taken for the answer to this search.
+	for e.stale > 0 {
+		line, err := e.readLine(ctx)
+		if err != nil {
+			return rules.Move{}, fmt.Errorf("external engine %s: %v", e.name, err)
+		}
+		if strings.HasPrefix(line, "bestmove ") {
+			e.stale--
+		}
+	}
+	if err := e.send(positionCommand(g)); err != nil {
+		return rules.Move{}, err
+	}
+	goCmd := "go"
+	if deadline, ok := ctx.Deadline(); ok {
+		goCmd = fmt.Sprintf("go movetime %d", time.Until(deadline).Milliseconds())
+	}
+	if err := e.send(goCmd); err != nil {
+		return rules.Move{}, err
+	}
+	for {
+		line, err := e.readLine(ctx)
+		if err != nil {
+			e.stale++
+			return rules.Move{}, fmt.Errorf("external engine %s: %v", e.name, err)
+		}
+		if s, ok := strings.CutPrefix(line, "bestmove "); ok {
+			return rules.ParseMove(s)
+		}
+	}
+}
+
+// Close asks the engine to quit and kills it if it does not.
+func (e *External) Close() error {
+	e.send("quit")
+	e.stdin.Close()
+	done := make(chan error, 1)
+	go func() { done <- e.cmd.Wait() }()
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(time.Second):
+		e.cmd.Process.Kill()
+		return <-done
+	}
+}
+
+func (e *External) send(line string) error {
+	_, err := io.WriteString(e.stdin, line+"\n")
+	return err
+}
+
+// readLine waits for the next output line. A passed deadline gets a second
+// of grace so that engines honouring movetime are not cut short; any other
+// end of ctx returns at once.
+func (e *External) readLine(ctx context.Context) (string, error) {
+	select {
+	case line, ok := <-e.lines:
+		return received(line, ok)
+	case <-ctx.Done():
+	}
+	if ctx.Err() != context.DeadlineExceeded {
+		return "", ctx.Err()
+	}
+	select {
+	case line, ok := <-e.lines:
+		return received(line, ok)
+	case <-time.After(readGrace):
+		return "", context.DeadlineExceeded
+	}
+}
+
+// readGrace is how long readLine waits past a deadline.
+const readGrace = time.Second
+
+// received is the result of a receive from the engine's output lines.
+func received(line string, ok bool) (string, error) {
+	if !ok {
+		return "", fmt.Errorf("engine exited")
+	}
+	return line, nil
+}
@@ -0,0 +1,151 @@
+package engine
+
+// The engine protocol is a line-based text protocol over stdin/stdout,
+// modelled on UCI. The controller sends:
+//
+//	bcp                                  start, engine replies "id name <name>" then "bcpok"
+//	isready                              engine replies "readyok"
+//	newgame                              forget any state from a previous game
+//	position startpos [moves m1 m2 ...]  set the game to search
+//	position pos <position string> [moves m1 m2 ...]
+//	go [depth <n>] [movetime <ms>]       engine replies "bestmove <move>"
+//	quit
+//
+// Moves and positions use the notation of the rules package. Unknown
+// commands are ignored.
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/baag_chal_gl/rules"
+)
+
+// Serve answers protocol commands read from in using e, until "quit" or the
+// end of input.
+func Serve(in io.Reader, out io.Writer, e Engine) error {
+	game := rules.NewGame(rules.NewPosition())
+	sc := bufio.NewScanner(in)
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		switch fields[0] {
+		case "bcp":
+			fmt.Fprintf(out, "id name %s\nbcpok\n", e.Name())
+		case "isready":
+			fmt.Fprintln(out, "readyok")
+		case "newgame":
+			game = rules.NewGame(rules.NewPosition())
+		case "position":
+			g, err := parsePositionCommand(fields[1:])
+			if err != nil {
+				fmt.Fprintf(out, "info error %v\n", err)
+				continue
+			}
+			game = g
+		case "go":
+			ctx, cancel, search := goContext(fields[1:], e)
+			m, err := search.BestMove(ctx, game)
+			cancel()
+			if err != nil {
+				fmt.Fprintf(out, "info error %v\nbestmove none\n", err)
+				continue
+			}
+			fmt.Fprintf(out, "bestmove %s\n", m)
+		case "quit":
+			return nil
+		}
+	}
+	return sc.Err()
+}
+
+// goContext applies the limits of a "go" command, returning the engine to
+// search with. A depth limit only applies to the built-in searcher, and only
+// to this search.
+func goContext(args []string, e Engine) (context.Context, context.CancelFunc, Engine) {
+	ctx := context.Background()
+	cancel := context.CancelFunc(func() {})
+	for i := 0; i+1 < len(args); i += 2 {
+		n, err := strconv.Atoi(args[i+1])
+		if err != nil {
+			continue
+		}
+		switch args[i] {
+		case "movetime":
+			ctx, cancel = context.WithTimeout(ctx, time.Duration(n)*time.Millisecond)
+		case "depth":
+			if s, ok := e.(*Searcher); ok {
+				limited := *s
+				limited.cfg.Depth = n
+				e = &limited
+			}
+		}
+	}
+	return ctx, cancel, e
+}
+
+// parsePositionCommand parses the arguments of a "position" command.
+func parsePositionCommand(args []string) (*rules.Game, error) {
+	if len(args) == 0 {
+		return nil, fmt.Errorf("position: missing arguments")
+	}
+	start := rules.NewPosition()
+	rest := args[1:]
+	switch args[0] {
+	case "startpos":
+	case "pos":
+		if len(rest) < 4 {
+			return nil, fmt.Errorf("position: incomplete position string")
+		}
+		p, err := rules.ParsePosition(strings.Join(rest[:4], " "))
+		if err != nil {
+			return nil, err
+		}
+		start = p
+		rest = rest[4:]
+	default:
+		return nil, fmt.Errorf("position: unknown kind %q", args[0])
+	}
+
+	game := rules.NewGame(start)
+	if len(rest) == 0 {
+		return game, nil
+	}
+	if rest[0] != "moves" {
+		return nil, fmt.Errorf("position: unexpected %q", rest[0])
+	}
+	for _, s := range rest[1:] {
+		m, err := rules.ParseMove(s)
+		if err != nil {
+			return nil, err
+		}
+		if err := game.Play(m); err != nil {
+			return nil, fmt.Errorf("position: move %s: %v", s, err)
+		}
+	}
+	return game, nil
+}
+
+// positionCommand is the "position" command describing g.
+func positionCommand(g *rules.Game) string {
+	var b strings.Builder
+	if g.Start == rules.NewPosition() {
+		b.WriteString("position startpos")
+	} else {
+		b.WriteString("position pos " + g.Start.String())
+	}
+	if len(g.Moves) > 0 {
+		b.WriteString(" moves")
+		for _, m := range g.Moves {
+			b.WriteString(" " + m.String())
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,190 @@
+package engine
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	"github.com/baag_chal_gl/rules"
+)
+
+// Config tunes the built-in searcher.
+type Config struct {
+	Name string
+	// Depth is the search depth in plies; 0 plays a random legal move.
+	Depth int
+	// Noise adds a random bonus of up to Noise points to each root move so
+	// weaker settings vary their play.
+	Noise int
+	// Seed seeds the random source; 0 picks a time-based seed.
+	Seed int64
+}
+
+const (
+	winScore = 100000
+	// nodesPerCheck is how many nodes are searched between deadline checks.
+	nodesPerCheck = 4096
+)
+
+// Searcher is an alpha-beta searcher with a hand-written evaluation.
+type Searcher struct {
+	cfg   Config
+	rng   *rand.Rand
+	nodes int
+	ctx   context.Context
+}
+
+// NewSearcher creates a built-in engine with the given settings.
+func NewSearcher(cfg Config) *Searcher {
+	seed := cfg.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	return &Searcher{cfg: cfg, rng: rand.New(rand.NewSource(seed))}
+}
+
+func (s *Searcher) Name() string { return s.cfg.Name }
+
+func (s *Searcher) Close() error { return nil }
+
+// BestMove searches with iterative deepening up to the configured depth or
+// until ctx is done, returning the best move of the last completed depth.
+func (s *Searcher) BestMove(ctx context.Context, g *rules.Game) (rules.Move, error) {
+	pos := g.Position()
+	moves := pos.LegalMoves()
+	if len(moves) == 0 {
+		return rules.Move{}, rules.ErrGameOver
+	}
+	if s.cfg.Depth <= 0 || len(moves) == 1 {
+		return moves[s.rng.Intn(len(moves))], nil
+	}
+
+	// Fixed per-move noise so that the choice is stable across depths.
+	noise := make([]int, len(moves))
+	if s.cfg.Noise > 0 {
+		for i := range noise {
+			noise[i] = s.rng.Intn(s.cfg.Noise + 1)
+		}
+	}
+
+	s.ctx = ctx
+	s.nodes = 0
+	best := moves[0]
+	for depth := 1; depth <= s.cfg.Depth; depth++ {
+		bestScore := -winScore * 2
+		var depthBest rules.Move
+		aborted := false
+		for i, m := range moves {
+			child := pos
+			child.Play(m)
+			var score int
+			if repeatsTwice(g, child) {
+				score = 0
+			} else {
+				score = -s.negamax(child, depth-1, -winScore*2, -bestScore+noise[i], 1)
+			}
+			if s.aborted() {
+				aborted = true
+				break
+			}
+			score += noise[i]
+			if score > bestScore {
+				bestScore = score
+				depthBest = m
+			}
+		}
+		if aborted {
+			break
+		}
+		best = depthBest
+		if bestScore >= winScore-100 {
+			break
+		}
+	}
+	return best, nil
+}
+
+// repeatsTwice reports whether p has already occurred twice in g, so that
+// reaching it again would draw.
+func repeatsTwice(g *rules.Game, p rules.Position) bool {
+	n := 0
+	for i := 0; i <= g.Ply(); i++ {
+		if g.PositionAt(i) == p {
+			n++
+		}
+	}
+	return n >= 2
+}
+
+func (s *Searcher) aborted() bool {
+	return s.ctx.Err() != nil
+}
+
+func (s *Searcher) negamax(pos rules.Position, depth, alpha, beta, ply int) int {
+	s.nodes++
+	if s.nodes%nodesPerCheck == 0 && s.aborted() {
+		return 0
+	}
+	if pos.CapturedGoats >= rules.GoatsToWin {
+		// The side to move (goats) has lost.
+		return -winScore + ply
+	}
+	moves := pos.LegalMoves()
+	if len(moves) == 0 {
+		return -winScore + ply
+	}
+	if depth <= 0 {
+		return evaluate(&pos)
+	}
+	for _, m := range moves {
+		child := pos
+		child.Play(m)
+		score := -s.negamax(child, depth-1, -beta, -alpha, ply+1)
+		if score > alpha {
+			alpha = score
+			if alpha >= beta {
+				break
+			}
+		}
+	}
+	return alpha
+}
+
+// evaluate scores pos for the side to move.
+func evaluate(pos *rules.Position) int {
+	score := tigerScore(pos)
+	if pos.Turn == rules.Goat {
+		return -score
+	}
+	return score
+}
+
+// tigerScore is the evaluation from the tigers' point of view.
+func tigerScore(pos *rules.Position) int {
+	score := 300 * pos.CapturedGoats
+
+	asTiger := *pos
+	asTiger.Turn = rules.Tiger
+	mobility := [rules.Size][rules.Size]int{}
+	threatened := map[[2]int]bool{}
+	for _, m := range asTiger.LegalMoves() {
+		mobility[m.From[0]][m.From[1]]++
+		if m.IsJump() {
+			threatened[m.Captured()] = true
+		}
+	}
+	for x := 0; x < rules.Size; x++ {
+		for y := 0; y < rules.Size; y++ {
+			if pos.Board[x][y] != rules.Tiger {
+				continue
+			}
+			if mobility[x][y] == 0 {
+				score -= 60
+			} else {
+				score += 4 * mobility[x][y]
+			}
+		}
+	}
+	score += 40 * len(threatened)
+	return score
+}
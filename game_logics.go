@@ -1,6 +1,11 @@
 package main
 
-import "log"
+import (
+	"log"
+
+	"github.com/baag_chal_gl/rules"
+	"github.com/go-gl/glfw/v3.3/glfw"
+)
 
 var (
 	// 0 = empty, 1 = goat, 2 = tiger
@@ -13,13 +18,20 @@ var (
 	capturedGoats = 0
 	gameOver = false
 
-	// Dragging state
+	// Dragging state; with click-to-move pieceSelected marks a piece
+	// picked up by a click instead
 	draggingPiece  bool
+	pieceSelected  bool
 	selectedPiece  = [2]int{-1, -1}
 	currentDragPos = [2]float32{0.0, 0.0}
+
+	// history is the game so far; the board always shows its position
+	history = rules.NewGame(rules.NewPosition())
+	// startPosition is where new games begin
+	startPosition = rules.NewPosition()
 )
 
-const maxGoats = 20
+const maxGoats = rules.MaxGoats
 
 var (
   tigerTex uint32
@@ -27,290 +39,241 @@ var (
 )
 
 
-
-var validConnections = map[[2]int][][2]int{
-	{0, 0}: {{0, 1}, {1, 1}, {1, 0}},
-	{0, 1}: {{0, 0}, {0, 2}, {1, 1}},
-	{0, 2}: {{0, 1}, {0, 3}, {1, 1}, {1, 2},{1,3}},
-	{0, 3}: {{0, 2}, {0, 4}, {1, 3}},
-	{0, 4}: {{0, 3}, {1, 3}, {1, 4}},
-
-	{1, 0}: {{0, 0}, {1, 1}, {2, 0}},
-	{1, 1}: {{0, 0}, {0, 1}, {0, 2}, {1, 0}, {1, 2}, {2, 0}, {2, 2}, {2, 1}},
-	{1, 2}: {{0, 2}, {1, 1}, {1, 3}, {2, 2}},
-	{1, 3}: {{0, 2},{0, 3}, {0, 4}, {1, 2}, {1, 4}, {2, 4}, {2, 2}, {2, 3}},
-	{1, 4}: {{0, 4}, {1, 3}, {2, 4}},
-
-	{2, 0}: {{1, 0}, {2, 1}, {3, 0}, {3,1}, {1,1}},
-	{2, 1}: {{1, 1}, {2, 0}, {2, 2}, {3, 1}},
-	{2, 2}: {{1, 1}, {1, 2}, {1, 3}, {2, 1}, {2, 3}, {3, 1}, {3, 3}, {3, 2}},
-	{2, 3}: {{1, 3}, {2, 2}, {2, 4}, {3, 3}},
-	{2, 4}: {{1, 3},{1, 4}, {2, 3}, {3,3}, {3, 4}},
-
-	{3, 0}: {{2, 0}, {3, 1}, {4, 0}},
-	{3, 1}: {{2, 0}, {2, 1}, {2, 2}, {3, 0}, {3, 2}, {4, 0}, {4, 2}, {4, 1}},
-	{3, 2}: {{2, 2}, {3, 1}, {3, 3}, {4, 2}},
-	{3, 3}: {{2,2},{2, 3}, {2, 4}, {3, 2}, {3, 4}, {4, 4}, {4, 2}, {4, 3}},
-	{3, 4}: {{2, 4}, {3, 3}, {4, 4}},
-
-	{4, 0}: {{3, 0}, {3,1}, {4, 1}, {4, 2}},
-	{4, 1}: {{3, 1}, {4, 0}, {4, 2}},
-	{4, 2}: {{3, 1}, {3, 2}, {3, 3}, {4, 1}, {4, 3}},
-	{4, 3}: {{3, 3}, {3, 4}, {4, 2}, {4, 4}},
-	{4, 4}: {{3, 3},{3, 4}, {4, 3}},
+// currentPosition gathers the board and counters into a rules.Position
+func currentPosition() rules.Position {
+	return rules.Position{
+		Board:         boardState,
+		Turn:          turn,
+		PlacedGoats:   placedGoats,
+		CapturedGoats: capturedGoats,
+	}
 }
 
-
-// checkTigerMoves returns true if at least one tiger can move
-func checkTigerMoves() bool {
-	// check if  if it has at least one valid move or capture.
-	for x := 0; x < 5; x++ {
-			for y := 0; y < 5; y++ {
-					if boardState[x][y] == 2 {
-							for nx := 0; nx < 5; nx++ {
-									for ny := 0; ny < 5; ny++ {
-											if isValidMove([2]int{x, y}, [2]int{nx, ny}) {
-													return true
-											}
-									}
-							}
-					}
-			}
-	}
-	return false
+// setPosition copies a rules.Position back into the board and counters
+func setPosition(p rules.Position) {
+	boardState = p.Board
+	turn = p.Turn
+	placedGoats = p.PlacedGoats
+	capturedGoats = p.CapturedGoats
 }
 
-func switchTurn() {
-	turn = 3 - turn
-	log.Printf("Turn switched to %d", turn)
+// startGame begins a new game from p
+func startGame(p rules.Position) {
+	history = rules.NewGame(p)
+	resetClock()
+	resumeLive()
+	clearSelection()
+	setPosition(p)
+}
 
-	// If it is now Tiger's turn, check if Tigers can move
-	if turn == 2 && !checkTigerMoves() {
-			gameOver = true
-			showDialog(
-					"Game Over",
-					"Goats win! Tigers have no valid moves.",
-					"goat_win_icon.png",
-					 // onNewGame callback
-					func() {
-						resetGame()
-				},
-					func() {
-						log.Println("User Canceled.Game remains over")
-					},
-			)
+// undoMove takes back the last move, or the last two when the engine
+// replied to it, so that it is the player's turn again.
+func undoMove() {
+	stopAI()
+	clearAnimations()
+	resumeLive()
+	clearSelection()
+	if !history.Undo() {
+		return
+	}
+	if aiToMove(history.Position()) && history.Ply() > 0 {
+		history.Undo()
 	}
+	setPosition(history.Position())
+	gameOver = false
+	log.Printf("Move taken back, %d moves played", history.Ply())
 }
 
-// placeGoat puts a goat on the board
-func placeGoat(x, y int) {
-	boardState[x][y] = 1
-	placedGoats++
-	log.Printf("Goat placed at (%d, %d). Total placed: %d", x, y, placedGoats)
+// submitMove plays a move through the rules engine. Every kind of input
+// ends up here so the GUI can never disagree with the rules. The piece
+// animates from its origin point, which is how engine and network moves
+// appear on the board.
+func submitMove(m rules.Move) bool {
+	return playMove(m, boardPoint(m.From))
 }
 
+// submitDrop plays a move made by dragging a piece to dropAt in NDC. An
+// illegal drop sends the piece back to where it came from.
+func submitDrop(m rules.Move, dropAt [2]float32) bool {
+	piece := boardState[m.From[0]][m.From[1]]
+	if playMove(m, dropAt) {
+		return true
+	}
+	animateSnapBack(piece, dropAt, m.From)
+	return false
+}
 
-//  handles finalizing a move for the piece being dragged.
-func onPieceRelease(boardX, boardY int) {
+// playMove applies m and starts its animation from the given NDC point.
+func playMove(m rules.Move, from [2]float32) bool {
 	if gameOver {
-		return
-}
-	draggingPiece = false
-	from := selectedPiece
-	to := [2]int{boardX, boardY}
+		return false
+	}
+	before := history.Position()
+	piece := rules.Goat
+	if !m.IsPlacement() {
+		piece = before.At(m.From)
+	}
+	if err := history.Play(m); err != nil {
+		log.Printf("Invalid move %s: %v", m, err)
+		onGameEvent(rules.EventIllegal)
+		return false
+	}
+	pressClock(before.Turn)
+	for _, e := range history.MoveEvents() {
+		onGameEvent(e)
+	}
+	// Finish any animation still running from the previous move first
+	skipAnimations()
+	setPosition(history.Position())
+	announceMove(before, m)
+	// A move arriving while an earlier position is shown is not animated
+	if !viewing() {
+		animateMove(m, piece, from)
+	}
 
-	if isValidMove(from, to) {
-			if canCapture(from, to) {
-					captureGoat(from, to)
-			} else {
-					// Normal move
-					boardState[from[0]][from[1]] = 0
-					boardState[to[0]][to[1]] = turn
-			}
-			switchTurn()
-	} else {
-			log.Printf("Invalid move from (%d, %d) to (%d, %d)", from[0], from[1], to[0], to[1])
+	switch {
+	case m.IsPlacement():
+		log.Printf("Goat placed at (%d, %d). Total placed: %d", m.To[0], m.To[1], placedGoats)
+	case m.IsJump():
+		log.Printf("Goat captured! Total captured: %d", capturedGoats)
 	}
+	log.Printf("Turn switched to %d", turn)
 
-	selectedPiece = [2]int{-1, -1}
+	checkGameOver()
+	return true
 }
 
-
-// canCapture checks if there's a goat in the midpoint when jumping 2 steps
-func canCapture(from, to [2]int) bool {
-	dx := to[0] - from[0]
-	dy := to[1] - from[1]
-
-	// Only a 2-step jump can capture
-	if !((abs(dx) == 2 && dy == 0) ||
-			(dx == 0 && abs(dy) == 2) ||
-			(abs(dx) == 2 && abs(dy) == 2)) {
-			return false
+// checkGameOver shows the game-over dialog once the game is decided
+func checkGameOver() {
+	p := history.Position()
+	var title, message string
+	var winner int
+	switch history.Result() {
+	case rules.TigersWin:
+		title, message, winner = "Tiger wins!", "5 goats have been captured.", rules.Tiger
+		if p.CapturedGoats < rules.GoatsToWin {
+			message = "The goats have no valid moves."
+		}
+		log.Printf("笑****** TIGER HAS WON! *****笑")
+	case rules.GoatsWin:
+		title, message, winner = "Goats win!", "The tigers are trapped and have no valid moves.", rules.Goat
+	case rules.Draw:
+		title, message = "Draw", "The same position has occurred three times."
+	default:
+		return
 	}
 
-	midX := (from[0] + to[0]) / 2
-	midY := (from[1] + to[1]) / 2
-
-log.Printf("Midpoint at (%d, %d) has state: %d", midX, midY, boardState[midX][midY])
-log.Printf("Destination at (%d, %d) has state: %d", to[0], to[1], boardState[to[0]][to[1]])
-
-	// Check if the midpoint is a goat
-	if boardState[midX][midY] != 1 {
-			return false
-	}
+	gameOver = true
+	announce(title + " " + message)
+	// Let the final move finish animating before the dialog covers it
+	whenAnimationsDone(func() {
+		pushDialog(&dialog{
+			Title:     "Game Over - " + title,
+			Message:   message + " Start a new game?",
+			IconPiece: winner,
+			Buttons: []dialogButton{
+				{Label: "New game", Color: okColor, Default: true, OnClick: resetGame},
+				{Label: "Close", Color: cancelColor, Cancel: true, OnClick: func() {
+					log.Println("User Canceled.Game remains over")
+				}},
+			},
+		})
+	})
+}
 
-	// Check if the destination is empty
-	if boardState[to[0]][to[1]] != 0 {
-			return false
+// confirmReset asks before throwing away a game in progress.
+func confirmReset() {
+	if gameOver || history.Ply() == 0 {
+		resetGame()
+		return
 	}
+	pushDialog(&dialog{
+		Title:   "New game",
+		Message: "The current game will be lost. Start a new game?",
+		Buttons: []dialogButton{
+			{Label: "New game", Color: okColor, Default: true, OnClick: resetGame},
+			{Label: "Keep playing", Color: cancelColor, Cancel: true},
+		},
+	})
+}
 
-	return true
+// confirmQuit asks before closing the window.
+func confirmQuit(w *glfw.Window) {
+	pushDialog(&dialog{
+		Title:   "Quit Baag-Chal?",
+		Buttons: []dialogButton{
+			{Label: "Quit", Color: cancelColor, Default: true, OnClick: func() { w.SetShouldClose(true) }},
+			{Label: "Keep playing", Color: okColor, Cancel: true},
+		},
+	})
 }
 
-// captureGoat removes a goat from the midpoint and moves the tiger
-func captureGoat(from, to [2]int) {
-    midX := (from[0] + to[0]) / 2
-    midY := (from[1] + to[1]) / 2
-    boardState[midX][midY] = 0     // Remove the goat
-    boardState[from[0]][from[1]] = 0
-    boardState[to[0]][to[1]] = 2   // Move the tiger
-    capturedGoats++
-    log.Printf("Goat captured! Total captured: %d", capturedGoats)
-
-    // Check if 5 goats have been captured => Tiger wins
-    if capturedGoats == 5 {
-        gameOver = true
-				log.Printf("笑****** TIGER HAS WON! *****笑")
-        showDialog(
-            "Game Over",
-            "Tiger wins! 5 goats have been captured.",
-            "tiger_win_icon.png",
-						func() {
-							resetGame()
-					},
-						func() {
-							log.Println("User Canceled.Game remains over")
-						},
-        )
-    }
+
+//  handles finalizing a move for the piece being dragged.
+func onPieceRelease(boardX, boardY int) {
+	if gameOver {
+		return
 }
+	draggingPiece = false
+	from := selectedPiece
+	to := [2]int{boardX, boardY}
 
+	submitDrop(rules.Move{From: from, To: to}, currentDragPos)
 
-// Utility for integer absolute value
-func abs(n int) int {
-	if n < 0 {
-			return -n
-	}
-	return n
+	selectedPiece = [2]int{-1, -1}
 }
 
 func onGoatPress(boardX, boardY int) {
 	// 1) If fewer than 20 goats have been placed, place a new goat
-	if placedGoats < 20 {
+	if placedGoats < maxGoats {
 			if boardState[boardX][boardY] == 0 {
-					placeGoat(boardX, boardY)
-
-					// If the goats and tigers to alternate immediately:
-					switchTurn()
+					submitMove(rules.Place([2]int{boardX, boardY}))
 			}
 			return
 	}
+
+	// 2) Otherwise goats move like tigers
+	onPiecePress(boardX, boardY)
 }
 
-//  handles initiating a drag on a tiger piece
+//  handles a press while the tigers are to move
 func onTigerPress(boardX, boardY int) {
-	if boardState[boardX][boardY] == 2 {
-			draggingPiece = true
-			selectedPiece = [2]int{boardX, boardY}
-			log.Printf("Tiger selected at (%d, %d)", boardX, boardY)
-	}
+	onPiecePress(boardX, boardY)
 }
 
-func isValidMove(from, to [2]int) bool {
-	// 1. Range check
-	if to[0] < 0 || to[1] < 0 || to[0] >= 5 || to[1] >= 5 {
-			return false
-	}
-
-	// 2. Destination must be empty
-	if boardState[to[0]][to[1]] != 0 {
-			return false
-	}
-
-	dx := to[0] - from[0]
-	dy := to[1] - from[1]
-
-	// =========== SINGLE-STEP MOVE ===========
-	// Check if 'to' is directly connected to 'from'.
-	if (abs(dx) == 1 && dy == 0) ||
-		 (dx == 0 && abs(dy) == 1) ||
-		 (abs(dx) == 1 && abs(dy) == 1) {
-
-			// Single-step must appear in validConnections[from]
-			validMoves, exists := validConnections[from]
-			if !exists {
-					return false
-			}
-			// 'to' must be in that slice
-			for _, conn := range validMoves {
-					if conn == to {
-							return true
-					}
-			}
-			return false
-	}
-
-	// =========== TWO-STEP JUMP (CAPTURE) ===========
-	if (abs(dx) == 2 && dy == 0) ||
-		(dx == 0 && abs(dy) == 2) ||
-		(abs(dx) == 2 && abs(dy) == 2) {
-
-			// The midpoint must have a goat
-			midX := (from[0] + to[0]) / 2
-			midY := (from[1] + to[1]) / 2
-			if boardState[midX][midY] != 1 {
-					// No goat at midpoint => invalid jump
-					return false
-			}
-
-			// ensure the path from 'from' to 'mid'
-
-			// 2a) from -> mid
-			fromConns, exists := validConnections[from]
-			if !exists {
-					return false
+// onPiecePress picks up a piece of the side to move: it follows the mouse
+// until released, or with click-to-move stays selected until the next
+// click names its destination. Clicking the selected piece again puts it
+// down. Both ways end in submitMove.
+func onPiecePress(boardX, boardY int) {
+	at := [2]int{boardX, boardY}
+	if boardState[boardX][boardY] == turn {
+			if clickToMove && pieceSelected && selectedPiece == at {
+					clearSelection()
+					return
 			}
-			foundMid := false
-			for _, conn := range fromConns {
-					if conn == [2]int{midX, midY} {
-							foundMid = true
-							break
-					}
-			}
-			if !foundMid {
-					return false
-			}
-
-			// 2b) mid -> to
-			midConns, exists2 := validConnections[[2]int{midX, midY}]
-			if !exists2 {
-					return false
+			selectedPiece = at
+			if clickToMove {
+					pieceSelected = true
+			} else {
+					draggingPiece = true
 			}
-			foundTo := false
-			for _, conn := range midConns {
-					if conn == to {
-							foundTo = true
-							break
-					}
+			name := "Goat"
+			if turn == rules.Tiger {
+					name = "Tiger"
 			}
-			if !foundTo {
-					return false
+			log.Printf("%s selected at (%d, %d)", name, boardX, boardY)
+			return
+	}
+	if clickToMove && pieceSelected {
+			if submitMove(rules.Move{From: selectedPiece, To: at}) {
+					clearSelection()
 			}
-
-			// a valid two-step jump
-			return true
 	}
+}
 
-	// =========== OTHERWISE INVALID ===========
-	return false
+// clearSelection drops a click-to-move selection.
+func clearSelection() {
+	pieceSelected = false
+	selectedPiece = [2]int{-1, -1}
 }
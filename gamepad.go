@@ -0,0 +1,255 @@
+package main
+
+import (
+	"log"
+
+	"github.com/baag_chal_gl/rules"
+	"github.com/go-gl/glfw/v3.3/glfw"
+)
+
+// Gamepads drive the same board cursor as the keyboard. A lone controller
+// plays both sides; with two, the first plays the goats and the second the
+// tigers, and Back swaps them. Controllers can come and go at any time.
+//
+//	D-pad / left stick  move the cursor
+//	A                   place, pick up or move (same as Enter)
+//	B                   put down, return to the live game
+//	Y                   undo
+//	LB / RB             step through the move list
+//	Start               theme menu
+//	Back                swap sides
+
+// spectator marks controllers beyond the second, which cannot play.
+const spectator = -1
+
+const (
+	stickDeadzone = 0.5
+	// Holding the stick repeats the step after repeatDelay, then every
+	// repeatRate seconds.
+	repeatDelay = 0.35
+	repeatRate  = 0.15
+)
+
+type gamepad struct {
+	joy glfw.Joystick
+	// side is rules.Goat, rules.Tiger, 0 for both or spectator
+	side     int
+	buttons  [glfw.ButtonLast + 1]bool
+	stickDir [2]int
+	repeatAt float64
+}
+
+var gamepads []*gamepad
+
+var dpadSteps = map[glfw.GamepadButton][2]int{
+	glfw.ButtonDpadUp:    {0, 1},
+	glfw.ButtonDpadDown:  {0, -1},
+	glfw.ButtonDpadLeft:  {-1, 0},
+	glfw.ButtonDpadRight: {1, 0},
+}
+
+// initGamepads picks up controllers already plugged in and watches for
+// new ones.
+func initGamepads() {
+	glfw.SetJoystickCallback(onJoystick)
+	for j := glfw.Joystick1; j <= glfw.JoystickLast; j++ {
+		if j.IsGamepad() {
+			addGamepad(j)
+		}
+	}
+}
+
+func onJoystick(joy glfw.Joystick, event glfw.PeripheralEvent) {
+	switch event {
+	case glfw.Connected:
+		if !joy.IsGamepad() {
+			log.Printf("[Gamepad] %s has no gamepad mapping, ignoring it", joy.GetName())
+			return
+		}
+		addGamepad(joy)
+	case glfw.Disconnected:
+		removeGamepad(joy)
+	}
+}
+
+func addGamepad(joy glfw.Joystick) {
+	for _, g := range gamepads {
+		if g.joy == joy {
+			return
+		}
+	}
+	g := &gamepad{joy: joy, side: spectator}
+	gamepads = append(gamepads, g)
+	assignSides()
+	log.Printf("[Gamepad] %s connected, playing %s", joy.GetGamepadName(), padSideName(g.side))
+}
+
+func removeGamepad(joy glfw.Joystick) {
+	for i, g := range gamepads {
+		if g.joy == joy {
+			gamepads = append(gamepads[:i], gamepads[i+1:]...)
+			assignSides()
+			log.Printf("[Gamepad] Controller %d disconnected", joy)
+			return
+		}
+	}
+}
+
+// assignSides hands out the sides after a controller comes or goes.
+func assignSides() {
+	switch len(gamepads) {
+	case 0:
+		return
+	case 1:
+		gamepads[0].side = 0
+		return
+	}
+	a, b := gamepads[0], gamepads[1]
+	if a.side != rules.Goat && a.side != rules.Tiger || a.side == b.side {
+		a.side = rules.Goat
+	}
+	b.side = rules.Opponent(a.side)
+	for _, g := range gamepads[2:] {
+		g.side = spectator
+	}
+}
+
+// swapSides exchanges the sides of the first two controllers.
+func swapSides() {
+	if len(gamepads) < 2 {
+		return
+	}
+	a, b := gamepads[0], gamepads[1]
+	a.side, b.side = b.side, a.side
+	log.Printf("[Gamepad] Sides swapped: controller %d plays %s", a.joy, padSideName(a.side))
+	announce("Controllers swapped sides.")
+}
+
+func padSideName(side int) string {
+	switch side {
+	case 0:
+		return "both sides"
+	case spectator:
+		return "no side"
+	}
+	return sideName(side)
+}
+
+// updateGamepads polls every controller. Call it once per frame.
+func updateGamepads(now float64) {
+	for _, g := range gamepads {
+		st := g.joy.GetGamepadState()
+		if st == nil {
+			continue
+		}
+		for b := range g.buttons {
+			down := st.Buttons[b] == glfw.Press
+			if down && !g.buttons[b] {
+				g.press(glfw.GamepadButton(b))
+			}
+			g.buttons[b] = down
+		}
+		g.updateStick(st, now)
+	}
+}
+
+// canPlay reports whether this controller may move for the side to move.
+func (g *gamepad) canPlay() bool {
+	return g.side == 0 || g.side == history.Position().Turn
+}
+
+func (g *gamepad) press(b glfw.GamepadButton) {
+	// Like a key, any button first finishes running animations
+	if animating() {
+		skipAnimations()
+		return
+	}
+	// Dialogs are modal: A picks the focused button, B cancels
+	if dialogOpen() {
+		switch b {
+		case glfw.ButtonA:
+			dialogKey(glfw.KeyEnter, 0)
+		case glfw.ButtonB:
+			dialogKey(glfw.KeyEscape, 0)
+		case glfw.ButtonDpadLeft:
+			dialogKey(glfw.KeyLeft, 0)
+		case glfw.ButtonDpadRight:
+			dialogKey(glfw.KeyRight, 0)
+		}
+		return
+	}
+	// In the theme menu A or the d-pad try the next theme
+	if settingsOpen {
+		switch b {
+		case glfw.ButtonStart, glfw.ButtonB:
+			closeSettings()
+		case glfw.ButtonA, glfw.ButtonDpadUp, glfw.ButtonDpadDown:
+			cycleTheme()
+		}
+		return
+	}
+
+	if d, ok := dpadSteps[b]; ok {
+		if g.canPlay() {
+			moveCursor(d)
+		}
+		return
+	}
+	switch b {
+	case glfw.ButtonA:
+		if g.canPlay() {
+			keyboardConfirm()
+		}
+	case glfw.ButtonB:
+		if !cancelKeyboardSelection() && viewing() {
+			resumeLive()
+		}
+	case glfw.ButtonY:
+		undoMove()
+	case glfw.ButtonLeftBumper:
+		stepView(-1)
+	case glfw.ButtonRightBumper:
+		stepView(1)
+	case glfw.ButtonStart:
+		settingsOpen = true
+	case glfw.ButtonBack:
+		swapSides()
+	}
+}
+
+// updateStick turns the left stick into cursor steps in eight directions,
+// repeating while it is held.
+func (g *gamepad) updateStick(st *glfw.GamepadState, now float64) {
+	// GLFW's Y axis points down
+	x, y := st.Axes[glfw.AxisLeftX], -st.Axes[glfw.AxisLeftY]
+	var dir [2]int
+	switch {
+	case x > stickDeadzone:
+		dir[0] = 1
+	case x < -stickDeadzone:
+		dir[0] = -1
+	}
+	switch {
+	case y > stickDeadzone:
+		dir[1] = 1
+	case y < -stickDeadzone:
+		dir[1] = -1
+	}
+
+	if dir == [2]int{} {
+		g.stickDir = dir
+		return
+	}
+	if dir == g.stickDir {
+		if now < g.repeatAt {
+			return
+		}
+		g.repeatAt = now + repeatRate
+	} else {
+		g.stickDir = dir
+		g.repeatAt = now + repeatDelay
+	}
+	if !dialogOpen() && !settingsOpen && g.canPlay() {
+		moveCursor(dir)
+	}
+}
@@ -0,0 +1,399 @@
+//go:build imgui
+
+package main
+
+import (
+	"fmt"
+	"image"
+	"log"
+	"time"
+	"unsafe"
+
+	"github.com/AllenDang/cimgui-go/imgui"
+	"github.com/baag_chal_gl/rules"
+	"github.com/go-gl/glfw/v3.3/glfw"
+)
+
+// Dear ImGui panels drawn on top of the board. ImGui gets its input from
+// the game's GLFW callbacks and is rendered through gfx, so it works with
+// both renderers.
+
+var gui = struct {
+	window  *glfw.Window
+	fontTex uint32
+	last    float64
+
+	showSettings bool
+	showMoves    bool
+	showEngine   bool
+	showDebug    bool
+}{showMoves: true}
+
+var imguiKeys = map[glfw.Key]imgui.Key{
+	glfw.KeyTab:       imgui.KeyTab,
+	glfw.KeyLeft:      imgui.KeyLeftArrow,
+	glfw.KeyRight:     imgui.KeyRightArrow,
+	glfw.KeyUp:        imgui.KeyUpArrow,
+	glfw.KeyDown:      imgui.KeyDownArrow,
+	glfw.KeyPageUp:    imgui.KeyPageUp,
+	glfw.KeyPageDown:  imgui.KeyPageDown,
+	glfw.KeyHome:      imgui.KeyHome,
+	glfw.KeyEnd:       imgui.KeyEnd,
+	glfw.KeyInsert:    imgui.KeyInsert,
+	glfw.KeyDelete:    imgui.KeyDelete,
+	glfw.KeyBackspace: imgui.KeyBackspace,
+	glfw.KeySpace:     imgui.KeySpace,
+	glfw.KeyEnter:     imgui.KeyEnter,
+	glfw.KeyKPEnter:   imgui.KeyKeypadEnter,
+	glfw.KeyEscape:    imgui.KeyEscape,
+	glfw.KeyA:         imgui.KeyA,
+	glfw.KeyC:         imgui.KeyC,
+	glfw.KeyV:         imgui.KeyV,
+	glfw.KeyX:         imgui.KeyX,
+	glfw.KeyY:         imgui.KeyY,
+	glfw.KeyZ:         imgui.KeyZ,
+}
+
+func initGUI(w *glfw.Window) {
+	gui.window = w
+	imgui.CreateContext()
+	io := imgui.CurrentIO()
+	io.SetIniFilename("")
+	imgui.StyleColorsDark()
+
+	// Upload the font atlas through the game's renderer.
+	pixels, width, height, _ := io.Fonts().GetTextureDataAsRGBA32()
+	rgba := image.NewRGBA(image.Rect(0, 0, int(width), int(height)))
+	copy(rgba.Pix, unsafe.Slice((*byte)(pixels), len(rgba.Pix)))
+	gui.fontTex = gfx.newTexture(rgba)
+	gui.last = glfw.GetTime()
+}
+
+// guiFrame feeds ImGui the frame timing and window size and builds all
+// panels for this frame.
+func guiFrame() {
+	io := imgui.CurrentIO()
+	now := glfw.GetTime()
+	dt := float32(now - gui.last)
+	if dt <= 0 {
+		dt = 1.0 / 60
+	}
+	gui.last = now
+	io.SetDeltaTime(dt)
+	io.SetDisplaySize(imgui.Vec2{X: float32(view.winW), Y: float32(view.winH)})
+	if view.winW > 0 && view.winH > 0 {
+		io.SetDisplayFramebufferScale(imgui.Vec2{
+			X: float32(view.fbW) / float32(view.winW),
+			Y: float32(view.fbH) / float32(view.winH),
+		})
+	}
+
+	imgui.NewFrame()
+	drawMainMenu()
+	drawSettingsWindow()
+	drawMovesWindow()
+	drawEngineWindow()
+	drawDebugOverlay()
+	imgui.Render()
+}
+
+func drawMainMenu() {
+	if !imgui.BeginMainMenuBar() {
+		return
+	}
+	if imgui.BeginMenu("Game") {
+		if imgui.MenuItemBool("New game") {
+			confirmReset()
+		}
+		if imgui.MenuItemBool("Undo") {
+			undoMove()
+		}
+		if imgui.MenuItemBool("Export replay (GIF)") {
+			exportReplay()
+		}
+		imgui.Separator()
+		if imgui.MenuItemBool("Quit") {
+			confirmQuit(gui.window)
+		}
+		imgui.EndMenu()
+	}
+	if imgui.BeginMenu("View") {
+		imgui.MenuItemBoolPtr("Settings", "", &gui.showSettings)
+		imgui.MenuItemBoolPtr("Move list", "", &gui.showMoves)
+		imgui.MenuItemBoolPtr("Engine", "", &gui.showEngine)
+		imgui.MenuItemBoolPtr("Debug overlay", "", &gui.showDebug)
+		imgui.Separator()
+		imgui.MenuItemBoolPtr("Coordinates", "C", &boardView.coords)
+		imgui.MenuItemBoolPtr("Threats", "O", &threatOverlay)
+		if imgui.MenuItemBool("Flip board") {
+			rotateBoard(2)
+		}
+		imgui.EndMenu()
+	}
+	imgui.EndMainMenuBar()
+}
+
+func drawSettingsWindow() {
+	if !gui.showSettings {
+		return
+	}
+	imgui.SetNextWindowPosV(imgui.Vec2{X: 20, Y: 40}, imgui.CondFirstUseEver, imgui.Vec2{})
+	if imgui.BeginV("Settings", &gui.showSettings, imgui.WindowFlagsAlwaysAutoResize) {
+		if imgui.BeginCombo("Theme", theme.Name) {
+			for _, t := range themes {
+				if imgui.SelectableBoolV(t.Name, t == theme, 0, imgui.Vec2{}) {
+					if err := applyTheme(t); err != nil {
+						log.Printf("[Theme] %s: %v", t.ID, err)
+					}
+				}
+			}
+			imgui.EndCombo()
+		}
+		speed := float32(*animSpeed)
+		if imgui.SliderFloat("Animation speed", &speed, 0, 3) {
+			*animSpeed = float64(speed)
+		}
+		if imgui.BeginCombo("Highlights", currentPalette.Name) {
+			for _, p := range palettes {
+				if imgui.SelectableBoolV(p.Name, p == currentPalette, 0, imgui.Vec2{}) {
+					currentPalette = p
+				}
+			}
+			imgui.EndCombo()
+		}
+		click := clickToMove
+		if imgui.Checkbox("Click to move", &click) {
+			toggleInputStyle()
+		}
+		muted, musicOff := audio.mix.state()
+		music := !musicOff
+		if imgui.Checkbox("Mute", &muted) {
+			toggleMute()
+		}
+		imgui.SameLine()
+		if imgui.Checkbox("Music", &music) {
+			toggleMusic()
+		}
+		volume := float32(audio.mix.getVolume())
+		if imgui.SliderFloat("Volume", &volume, 0, 1) {
+			audio.mix.setVolume(float64(volume))
+		}
+		scale := float32(uiScaleValue)
+		if imgui.SliderFloat("UI scale", &scale, 0.75, 2) {
+			setUIScale(float64(scale))
+		}
+		if imgui.BeginCombo("Clock", clock.control.String()) {
+			for _, s := range timeControls {
+				if imgui.SelectableBoolV(s, s == clock.control.String(), 0, imgui.Vec2{}) {
+					tc, _ := parseTimeControl(s)
+					setTimeControl(tc)
+				}
+			}
+			imgui.EndCombo()
+		}
+		imgui.Text("Renderer: " + gfx.name())
+	}
+	imgui.End()
+}
+
+func drawMovesWindow() {
+	if !gui.showMoves {
+		return
+	}
+	imgui.SetNextWindowPosV(imgui.Vec2{X: float32(view.winW) - 220, Y: 40}, imgui.CondFirstUseEver, imgui.Vec2{})
+	imgui.SetNextWindowSizeV(imgui.Vec2{X: 200, Y: 300}, imgui.CondFirstUseEver)
+	if imgui.BeginV("Moves", &gui.showMoves, 0) {
+		// Each move jumps the board to the position after it, like the
+		// built-in move list.
+		if imgui.SelectableBoolV("Start", shownPly() == 0, 0, imgui.Vec2{}) {
+			viewAt(0)
+		}
+		for i, m := range history.Moves {
+			row, col := moveRowOf(i)
+			if col == 0 || i == 0 {
+				imgui.Text(fmt.Sprintf("%d.", row+1))
+			}
+			imgui.SameLine()
+			label := fmt.Sprintf("%s##%d", m, i)
+			if imgui.SelectableBoolV(label, shownPly() == i+1, 0, imgui.Vec2{X: 70}) {
+				viewAt(i + 1)
+			}
+		}
+		if viewing() && imgui.Button("Resume") {
+			resumeLive()
+		}
+	}
+	imgui.End()
+}
+
+
+func drawEngineWindow() {
+	if !gui.showEngine {
+		return
+	}
+	imgui.SetNextWindowPosV(imgui.Vec2{X: 20, Y: 200}, imgui.CondFirstUseEver, imgui.Vec2{})
+	if imgui.BeginV("Engine", &gui.showEngine, imgui.WindowFlagsAlwaysAutoResize) {
+		side, level := ai.side, ai.level
+		imgui.Text("Computer plays:")
+		if imgui.RadioButtonBool("Nobody", side == 0) {
+			side = 0
+		}
+		imgui.SameLine()
+		if imgui.RadioButtonBool("Goats", side == rules.Goat) {
+			side = rules.Goat
+		}
+		imgui.SameLine()
+		if imgui.RadioButtonBool("Tigers", side == rules.Tiger) {
+			side = rules.Tiger
+		}
+		if imgui.BeginCombo("Level", level) {
+			for _, name := range aiLevels {
+				if imgui.SelectableBoolV(name, name == level, 0, imgui.Vec2{}) {
+					level = name
+				}
+			}
+			imgui.EndCombo()
+		}
+		if side != ai.side || level != ai.level {
+			if err := setAI(side, level); err != nil {
+				log.Printf("[AI] %v", err)
+			}
+		}
+
+		ms := int32(ai.moveTime / time.Millisecond)
+		if imgui.SliderInt("Move time (ms)", &ms, 100, 10000) {
+			ai.moveTime = time.Duration(ms) * time.Millisecond
+		}
+		if ai.thinking {
+			imgui.Text("Thinking...")
+			imgui.SameLine()
+			if imgui.Button("Move now") {
+				aiMoveNow()
+			}
+		} else {
+			imgui.Text("Waiting")
+		}
+	}
+	imgui.End()
+}
+
+func drawDebugOverlay() {
+	if !gui.showDebug {
+		return
+	}
+	io := imgui.CurrentIO()
+	imgui.SetNextWindowPosV(imgui.Vec2{X: 10, Y: float32(view.winH) - 10}, imgui.CondAlways, imgui.Vec2{X: 0, Y: 1})
+	imgui.SetNextWindowBgAlpha(0.35)
+	flags := imgui.WindowFlagsNoDecoration | imgui.WindowFlagsAlwaysAutoResize |
+		imgui.WindowFlagsNoSavedSettings | imgui.WindowFlagsNoFocusOnAppearing |
+		imgui.WindowFlagsNoNav | imgui.WindowFlagsNoInputs
+	if imgui.BeginV("Debug", nil, flags) {
+		fps := io.Framerate()
+		imgui.Text(fmt.Sprintf("%.0f fps (%.2f ms)", fps, 1000/fps))
+		imgui.Text("Renderer: " + gfx.name())
+		imgui.Text(fmt.Sprintf("Window %dx%d, framebuffer %dx%d, scale %.2f",
+			view.winW, view.winH, view.fbW, view.fbH, view.contentScale))
+		imgui.Text(fmt.Sprintf("Board viewport %d,%d %dpx", view.vpX, view.vpY, view.vpSize))
+		imgui.Text(fmt.Sprintf("Ply %d, animations %d", history.Ply(), len(tweens)))
+		imgui.Text("Position: " + history.Position().String())
+	}
+	imgui.End()
+}
+
+// drawGUI renders this frame's ImGui draw lists over the whole window.
+func drawGUI() {
+	data := imgui.CurrentDrawData()
+	if data == nil || view.winW == 0 || view.winH == 0 {
+		return
+	}
+	gfx.flush()
+	gfx.viewport(0, 0, int32(view.fbW), int32(view.fbH))
+
+	vtxSize, posOffset, uvOffset, colOffset := imgui.VertexBufferLayout()
+	idxSize := imgui.IndexBufferLayout()
+	sx := float32(view.fbW) / float32(view.winW)
+	sy := float32(view.fbH) / float32(view.winH)
+
+	var verts []vertex
+	for _, list := range data.CommandLists() {
+		vbuf, _ := list.GetVertexBuffer()
+		ibuf, _ := list.GetIndexBuffer()
+		first := 0
+		for _, cmd := range list.Commands() {
+			n := int(cmd.ElemCount())
+			clip := cmd.ClipRect()
+			// ImGui clip rects are top-down in window units.
+			gfx.scissor(image.Rect(
+				int(clip.X*sx), view.fbH-int(clip.W*sy),
+				int(clip.Z*sx), view.fbH-int(clip.Y*sy),
+			))
+
+			verts = verts[:0]
+			for k := first; k < first+n; k++ {
+				p := unsafe.Add(ibuf, k*idxSize)
+				idx := int(*(*uint16)(p))
+				if idxSize == 4 {
+					idx = int(*(*uint32)(p))
+				}
+				v := unsafe.Add(vbuf, idx*vtxSize)
+				pos := (*[2]float32)(unsafe.Add(v, posOffset))
+				uv := (*[2]float32)(unsafe.Add(v, uvOffset))
+				col := *(*uint32)(unsafe.Add(v, colOffset))
+				verts = append(verts, vertex{
+					x: pos[0]/float32(view.winW)*2 - 1,
+					y: 1 - pos[1]/float32(view.winH)*2,
+					u: uv[0], v: uv[1],
+					color: [4]float32{
+						float32(col&0xff) / 255,
+						float32(col>>8&0xff) / 255,
+						float32(col>>16&0xff) / 255,
+						float32(col>>24&0xff) / 255,
+					},
+				})
+			}
+			if len(verts) > 0 {
+				gfx.drawTriangles(gui.fontTex, verts)
+			}
+			first += n
+		}
+	}
+	gfx.scissor(image.Rectangle{})
+	applyLayout()
+}
+
+// guiMouseButton passes a click to ImGui and reports whether ImGui wants
+// it, in which case the board must ignore it.
+func guiMouseButton(button glfw.MouseButton, action glfw.Action) bool {
+	io := imgui.CurrentIO()
+	if button < 5 {
+		io.AddMouseButtonEvent(int32(button), action == glfw.Press)
+	}
+	return action == glfw.Press && io.WantCaptureMouse()
+}
+
+func guiCursorPos(x, y float64) {
+	imgui.CurrentIO().AddMousePosEvent(float32(x), float32(y))
+}
+
+func guiScroll(x, y float64) {
+	imgui.CurrentIO().AddMouseWheelEvent(float32(x), float32(y))
+}
+
+// guiKey passes a key to ImGui and reports whether ImGui is using the
+// keyboard, e.g. while a text field has focus.
+func guiKey(key glfw.Key, action glfw.Action, mods glfw.ModifierKey) bool {
+	io := imgui.CurrentIO()
+	down := action != glfw.Release
+	io.AddKeyEvent(imgui.ModCtrl, mods&glfw.ModControl != 0)
+	io.AddKeyEvent(imgui.ModShift, mods&glfw.ModShift != 0)
+	io.AddKeyEvent(imgui.ModAlt, mods&glfw.ModAlt != 0)
+	io.AddKeyEvent(imgui.ModSuper, mods&glfw.ModSuper != 0)
+	if k, ok := imguiKeys[key]; ok {
+		io.AddKeyEvent(k, down)
+	}
+	return io.WantCaptureKeyboard()
+}
+
+func guiChar(r rune) {
+	imgui.CurrentIO().AddInputCharacter(uint32(r))
+}
@@ -0,0 +1,24 @@
+//go:build !imgui
+
+package main
+
+import "github.com/go-gl/glfw/v3.3/glfw"
+
+// Without the imgui build tag the game has only its built-in widgets and
+// these hooks do nothing.
+
+func initGUI(w *glfw.Window) {}
+
+func guiFrame() {}
+
+func drawGUI() {}
+
+func guiMouseButton(button glfw.MouseButton, action glfw.Action) bool { return false }
+
+func guiCursorPos(x, y float64) {}
+
+func guiScroll(x, y float64) {}
+
+func guiKey(key glfw.Key, action glfw.Action, mods glfw.ModifierKey) bool { return false }
+
+func guiChar(r rune) {}
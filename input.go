@@ -1,41 +1,100 @@
 package main
 
-import "github.com/go-gl/glfw/v3.3/glfw"
+import (
+	"flag"
+	"log"
+
+	"github.com/go-gl/glfw/v3.3/glfw"
+)
+
+var inputFlag = flag.String("input", "drag", "how to move pieces: drag, or click the piece then its destination")
+
+// clickToMove moves pieces with a click on the piece and a click on its
+// destination instead of dragging
+var clickToMove bool
+
+// initInputStyle applies the -input flag
+func initInputStyle() {
+	switch *inputFlag {
+	case "click":
+			clickToMove = true
+	case "drag":
+	default:
+			log.Printf("Unknown input style %q, using drag", *inputFlag)
+	}
+}
+
+// toggleInputStyle switches between dragging and click-to-move
+func toggleInputStyle() {
+	clickToMove = !clickToMove
+	clearSelection()
+	log.Printf("Input style: %s", inputStyleName())
+}
+
+func inputStyleName() string {
+	if clickToMove {
+			return "click"
+	}
+	return "drag"
+}
 
 
 func onMouseClick(w *glfw.Window, button glfw.MouseButton, action glfw.Action, mods glfw.ModifierKey) {
+	// Clicks over an ImGui window belong to it
+	if guiMouseButton(button, action) {
+			return
+	}
 	if button == glfw.MouseButtonLeft && action == glfw.Press {
 			mx, my := w.GetCursorPos()
 
-			// If dialog is active, intercept clicks for the dialog
-			if dialogActive {
+			// A click completes any running animation before acting; if that
+			// opens the game-over dialog, the click is used up
+			hadDialog := dialogOpen()
+			skipAnimations()
+			if dialogOpen() && !hadDialog {
+					return
+			}
+
+			// An open dialog is modal and takes every click
+			if dialogOpen() {
 					ndcX, ndcY := screenToNDC(mx, my)
-					// Check "Yes" button
-					if pointInRect(ndcX, ndcY, dialogButtonYesRect) {
-							if dialogOnNewGame != nil {
-									dialogOnNewGame()
-							}
-							dialogActive = false
-							return
-					}
-					// Check "No" button
-					if pointInRect(ndcX, ndcY, dialogButtonNoRect) {
-							if dailogOnCancel != nil {
-								dailogOnCancel()
-							}
-							dialogActive = false
-							return
-					}
-					// If clicked outside buttons, do nothing (stay in dialog).
+					dialogClick(ndcX, ndcY)
+					return
+			}
+
+			// The settings panel takes every click while it is open
+			if settingsOpen {
+					ndcX, ndcY := screenToNDC(mx, my)
+					settingsClick(ndcX, ndcY)
+					return
+			}
+			if ndcX, ndcY := screenToNDC(mx, my); pointInRect(ndcX, ndcY, settingsButtonRect) {
+					settingsOpen = true
+					return
+			}
+			if ndcX, ndcY := screenToNDC(mx, my); pointInRect(ndcX, ndcY, moveListButtonRect) {
+					toggleMoveList()
+					return
+			}
+			if ndcX, ndcY := screenToNDC(mx, my); moveListOpen && moveListClick(ndcX, ndcY) {
 					return
 			}
 
 			// If no dialog: check if we clicked Reset
 			if isOverResetButton(mx, my) {
-					resetGame()
+					confirmReset()
+					return
+			}
+
+			// The board is view-only while an earlier position is shown
+			if viewing() {
 					return
 			}
 
+			// Playing with the mouse hides the keyboard cursor
+			kb.active = false
+			kb.selected = noPoint
+
 			// Normal gameplay logic: placing goats, dragging tigers, etc.
 			boardX, boardY := screenToBoardCoords(mx, my)
 			if boardX == -1 || boardY == -1 {
@@ -63,6 +122,11 @@ func onMouseClick(w *glfw.Window, button glfw.MouseButton, action glfw.Action, m
 
 //  updates the currentDragPos if dragging a piece
 func onMouseMove(w *glfw.Window, xpos float64, ypos float64) {
+	guiCursorPos(xpos, ypos)
+	if dialogOpen() {
+			dialogHover(screenToNDC(xpos, ypos))
+			return
+	}
 	if draggingPiece {
 			ndcX, ndcY := screenToNDC(xpos, ypos)
 			currentDragPos[0] = ndcX
@@ -72,13 +136,126 @@ func onMouseMove(w *glfw.Window, xpos float64, ypos float64) {
 
 // onKeyPress can handle ESC to close or other shortcuts
 func onKeyPress(w *glfw.Window, key glfw.Key, scancode int, action glfw.Action, mods glfw.ModifierKey) {
+	if guiKey(key, action, mods) {
+			return
+	}
+	if (action == glfw.Press || action == glfw.Repeat) && !dialogOpen() && !settingsOpen {
+			// Left and right step through the game while the move list is
+			// open or an earlier position is shown
+			if moveListOpen || viewing() {
+					switch key {
+					case glfw.KeyLeft:
+							stepView(-1)
+							return
+					case glfw.KeyRight:
+							stepView(1)
+							return
+					}
+			}
+			// Otherwise the arrows and keypad move the board cursor
+			if d, ok := cursorKeys[key]; ok {
+					moveCursor(d)
+					return
+			}
+	}
 	if action == glfw.Press {
-			if key == glfw.KeyEscape {
-					w.SetShouldClose(true)
+			// Any key skips running animations
+			if animating() {
+					skipAnimations()
+					return
+			}
+			// Dialogs are modal and take the keyboard
+			if dialogOpen() {
+					dialogKey(key, mods)
+					return
+			}
+			switch key {
+			case glfw.KeyEscape:
+					// Escape closes the settings panel first, then returns to
+					// the live game, then asks to quit
+					if settingsOpen {
+							closeSettings()
+							return
+					}
+					if pieceSelected {
+							clearSelection()
+							return
+					}
+					if cancelKeyboardSelection() {
+							announce("Put down.")
+							return
+					}
+					if viewing() {
+							resumeLive()
+							return
+					}
+					confirmQuit(w)
+			case glfw.KeyT:
+					cycleTheme()
+			case glfw.KeyEnter, glfw.KeyKPEnter, glfw.KeySpace:
+					keyboardConfirm()
+			case glfw.KeyF2:
+					dumpAccessibility(w)
+			case glfw.KeyP:
+					cyclePalette()
+			case glfw.KeyEqual, glfw.KeyKPAdd:
+					if key == glfw.KeyKPAdd || mods&glfw.ModControl != 0 {
+							setUIScale(uiScaleValue + 0.25)
+					}
+			case glfw.KeyMinus, glfw.KeyKPSubtract:
+					if key == glfw.KeyKPSubtract || mods&glfw.ModControl != 0 {
+							setUIScale(uiScaleValue - 0.25)
+					}
+			case glfw.KeyI:
+					toggleInputStyle()
+			case glfw.KeyS:
+					toggleMute()
+			case glfw.KeyB:
+					toggleMusic()
+			case glfw.KeyLeftBracket:
+					audio.mix.setVolume(audio.mix.getVolume() - 0.1)
+			case glfw.KeyRightBracket:
+					audio.mix.setVolume(audio.mix.getVolume() + 0.1)
+			case glfw.KeyM:
+					toggleMoveList()
+			case glfw.KeyG:
+					exportReplay()
+			case glfw.KeyF:
+					rotateBoard(2)
+			case glfw.KeyR:
+					// Shift+R turns the other way
+					if mods&glfw.ModShift != 0 {
+							rotateBoard(-1)
+					} else {
+							rotateBoard(1)
+					}
+			case glfw.KeyC:
+					toggleCoords()
+			case glfw.KeyO:
+					toggleThreats()
+			case glfw.KeyHome:
+					viewAt(0)
+			case glfw.KeyEnd:
+					resumeLive()
+			case glfw.KeyU:
+					undoMove()
+			case glfw.KeyZ:
+					if mods&glfw.ModControl != 0 {
+							undoMove()
+					}
 			}
 	}
 }
 
+// onChar and onScroll only matter to ImGui
+func onChar(w *glfw.Window, char rune) {
+	guiChar(char)
+}
+
+func onScroll(w *glfw.Window, xoff float64, yoff float64) {
+	guiScroll(xoff, yoff)
+}
+
 // Helper: check if a point is inside a rect in NDC
 func pointInRect(x, y float32, r struct{x1,y1,x2,y2 float32}) bool {
 	return x >= r.x1 && x <= r.x2 && y <= r.y1 && y >= r.y2
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"log"
+	"math"
+
+	"github.com/baag_chal_gl/rules"
+	"github.com/go-gl/glfw/v3.3/glfw"
+)
+
+// Keyboard play: a cursor walks the board along its lines and Enter places
+// a goat, picks up a piece or moves the picked-up piece to the cursor.
+// Moves go through submitMove like every other input.
+
+var kb = struct {
+	cursor [2]int
+	// active is set once the keyboard is used, so the cursor only shows
+	// for keyboard players
+	active   bool
+	selected [2]int
+}{cursor: [2]int{2, 2}, selected: noPoint}
+
+var noPoint = [2]int{-1, -1}
+
+// cursorKeys maps keys to cursor steps. The arrows move along ranks and
+// files; the keypad corners follow the diagonals where the board has them.
+var cursorKeys = map[glfw.Key][2]int{
+	glfw.KeyUp:    {0, 1},
+	glfw.KeyDown:  {0, -1},
+	glfw.KeyLeft:  {-1, 0},
+	glfw.KeyRight: {1, 0},
+	glfw.KeyKP8:   {0, 1},
+	glfw.KeyKP2:   {0, -1},
+	glfw.KeyKP4:   {-1, 0},
+	glfw.KeyKP6:   {1, 0},
+	glfw.KeyKP7:   {-1, 1},
+	glfw.KeyKP9:   {1, 1},
+	glfw.KeyKP1:   {-1, -1},
+	glfw.KeyKP3:   {1, -1},
+}
+
+// moveCursor steps the cursor by d, a direction on screen, if a line joins
+// the two points.
+func moveCursor(d [2]int) {
+	kb.active = true
+	d = turnDelta(d, 4-boardTurns())
+	to := [2]int{kb.cursor[0] + d[0], kb.cursor[1] + d[1]}
+	if !rules.OnBoard(to) || !rules.Connected(kb.cursor, to) {
+		announce("No line leads that way.")
+		return
+	}
+	kb.cursor = to
+	p := history.Position()
+	announce(describePoint(&p, to))
+}
+
+// keyboardSelection returns the picked-up point if it still holds a piece
+// of the side to move.
+func keyboardSelection() ([2]int, bool) {
+	p := history.Position()
+	if kb.selected == noPoint || p.At(kb.selected) != p.Turn {
+		return noPoint, false
+	}
+	return kb.selected, true
+}
+
+// keyboardConfirm acts on the point under the cursor.
+func keyboardConfirm() {
+	kb.active = true
+	if gameOver || viewing() || aiToMove(history.Position()) {
+		return
+	}
+	p := history.Position()
+	at := kb.cursor
+
+	if p.Turn == rules.Goat && p.PlacedGoats < rules.MaxGoats {
+		submitMove(rules.Place(at))
+		return
+	}
+
+	from, ok := keyboardSelection()
+	switch {
+	case p.At(at) == p.Turn && at == from:
+		kb.selected = noPoint
+		announce("Put down.")
+	case p.At(at) == p.Turn:
+		kb.selected = at
+		log.Printf("Piece selected at (%d, %d)", at[0], at[1])
+		announce("Picked up " + describePoint(&p, at) + ".")
+	case ok:
+		if submitMove(rules.Move{From: from, To: at}) {
+			kb.selected = noPoint
+		} else {
+			announce("Illegal move.")
+		}
+	}
+}
+
+// cancelKeyboardSelection puts down a picked-up piece. It reports whether
+// there was one.
+func cancelKeyboardSelection() bool {
+	if _, ok := keyboardSelection(); !ok {
+		return false
+	}
+	kb.selected = noPoint
+	return true
+}
+
+// drawKeyboardCursor rings the cursor point and the picked-up piece.
+func drawKeyboardCursor() {
+	if !kb.active || viewing() {
+		return
+	}
+	colors := highlightColors()
+	if from, ok := keyboardSelection(); ok {
+		p := boardPoint(from)
+		drawCircle(p[0], p[1], 0.075, 32, colors.Highlight)
+	}
+	p := boardPoint(kb.cursor)
+	drawRing(p[0], p[1], 0.09, 4, colors.Cursor)
+}
+
+// drawRing draws a circle outline width pixels thick.
+func drawRing(x, y, radius, width float32, c [4]float32) {
+	const segments = 32
+	prev := [2]float32{x + radius, y}
+	for i := 1; i <= segments; i++ {
+		theta := 2 * math.Pi * float64(i) / segments
+		next := [2]float32{
+			x + radius*float32(math.Cos(theta)),
+			y + radius*float32(math.Sin(theta)),
+		}
+		gfx.line(prev[0], prev[1], next[0], next[1], width*view.contentScale*uiScale(), c)
+		prev = next
+	}
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"log"
+
+	"github.com/go-gl/glfw/v3.3/glfw"
+)
+
+// view is the live window geometry. Everything is drawn in NDC inside a
+// square viewport centred in the framebuffer, so the board keeps its aspect
+// ratio and the spare space becomes letterbox bars. The open move list
+// takes a strip beside the board, so the board moves over to make room.
+var view = struct {
+	// window size in screen coordinates, the unit of cursor positions
+	winW, winH int
+	// framebuffer size in pixels
+	fbW, fbH int
+	// contentScale is the monitor's UI scale (2 on most hi-DPI displays)
+	contentScale float32
+
+	// square board viewport in framebuffer pixels, origin bottom-left
+	vpX, vpY, vpSize int
+}{
+	winW: windowWidth, winH: windowHeight,
+	fbW: windowWidth, fbH: windowHeight,
+	contentScale: 1,
+	vpSize:       windowWidth,
+}
+
+// initLayout reads the initial geometry from the window and sets the
+// viewport.
+func initLayout(w *glfw.Window) {
+	view.winW, view.winH = w.GetSize()
+	view.fbW, view.fbH = w.GetFramebufferSize()
+	view.contentScale, _ = w.GetContentScale()
+	applyLayout()
+}
+
+// applyLayout recomputes the square viewport from the framebuffer size.
+func applyLayout() {
+	width := float64(view.fbW)
+	if moveListOpen {
+		width /= 1 + moveListReserve
+	}
+	size := int(width)
+	if view.fbH < size {
+		size = view.fbH
+	}
+	if size < 1 {
+		// Minimized: keep the last usable viewport.
+		return
+	}
+	used := size
+	if moveListOpen {
+		used = int(float64(size) * (1 + moveListReserve))
+	}
+	view.vpSize = size
+	view.vpX = (view.fbW - used) / 2
+	view.vpY = (view.fbH - size) / 2
+	gfx.viewport(int32(view.vpX), int32(view.vpY), int32(size), int32(size))
+}
+
+func onFramebufferResize(w *glfw.Window, width, height int) {
+	view.fbW, view.fbH = width, height
+	applyLayout()
+}
+
+func onWindowResize(w *glfw.Window, width, height int) {
+	view.winW, view.winH = width, height
+}
+
+func onContentScale(w *glfw.Window, x, y float32) {
+	view.contentScale = x
+	log.Printf("Content scale changed to %.2f", x)
+}
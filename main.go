@@ -1,10 +1,14 @@
 package main
 
 import (
+	"flag"
+	"fmt"
 	"log"
+	"os"
 	"runtime"
+	"strings"
 
-	"github.com/go-gl/gl/v2.1/gl"
+	"github.com/baag_chal_gl/assets"
 	"github.com/go-gl/glfw/v3.3/glfw"
 )
 
@@ -21,69 +25,145 @@ func init() {
 }
 
 func main() {
+    // The first argument picks a command; without one the game is played,
+    // so the game's flags can still be given on their own
+    name, args := "play", os.Args[1:]
+    if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+        name, args = args[0], args[1:]
+    }
+    flag.Usage = usage
+    if name == "help" {
+        usage()
+        return
+    }
+    for _, c := range commands {
+        if c.name == name {
+            os.Exit(c.run(args))
+        }
+    }
+    fmt.Fprintf(os.Stderr, "unknown command %q\n", name)
+    usage()
+    os.Exit(2)
+}
+
+// runPlay opens the board window. Its flags are the program's global
+// flags, which the other commands share a few of.
+func runPlay(args []string) int {
+    flag.CommandLine.Parse(args)
+    if flag.NArg() > 0 {
+        flag.Usage()
+        return 2
+    }
+    // Saved settings fill in the flags not given
+    loadSettings(flag.CommandLine)
+    if err := initStartPosition(); err != nil {
+        fmt.Fprintln(os.Stderr, "play:", err)
+        return 2
+    }
+
     if err := glfw.Init(); err != nil {
         log.Fatalln("failed to initialize glfw:", err)
     }
     defer glfw.Terminate()
 
-    window, err := glfw.CreateWindow(windowWidth, windowHeight, "Baag-Chal Board", nil, nil)
+    initAccessibility()
+    initInputStyle()
+    initOrientation()
+    threatOverlay = *threatsFlag
+
+    // Prefer the shader renderer, falling back to OpenGL 2.1 on old drivers
+    window, r, err := openWindow(config.file.WindowWidth, config.file.WindowHeight, "Baag-Chal Board", *rendererFlag)
     if err != nil {
         log.Fatalln("failed to create window:", err)
     }
-    window.MakeContextCurrent()
+    gfx = r
 
-    if err := gl.Init(); err != nil {
-        log.Fatalln("failed to initialize OpenGL:", err)
-    }
+    // Square, letterboxed board viewport that follows the window size
+    initLayout(window)
+    window.SetFramebufferSizeCallback(onFramebufferResize)
+    window.SetSizeCallback(onWindowResize)
+    window.SetContentScaleCallback(onContentScale)
+
+    // Start from -position, or with the tigers in the corners
+    initClock()
+    startGame(startPosition)
+
+    // Load the themes and the art of the selected one from the embedded
+    // assets, overlaid with the user's asset directory
+    initAssets()
 
-    gl.Viewport(0, 0, int32(windowWidth), int32(windowHeight))
-    gl.ClearColor(0.0, 0.0, 0.0, 1.0)
-
-    // Initialize the board with tigers in the corners
-    boardState[0][0] = 2
-    boardState[0][4] = 2
-    boardState[4][0] = 2
-    boardState[4][4] = 2
-
-    // Enable blending & texturing
-    gl.Enable(gl.BLEND)
-    gl.BlendFunc(gl.SRC_ALPHA, gl.ONE_MINUS_SRC_ALPHA)
-    gl.Enable(gl.TEXTURE_2D)
-
-			// loading font
-		if err := LoadFont("assets/Wasted-Vindey.ttf"); err != nil {
-    	log.Fatalln("Failed to load font:", err)
-		}
-    // Load textures
-    goatTex, err = LoadTexture("goat_1.png")
+    // Sound effects and music, or silence without a sound device
+    initAudio()
+    defer audio.backend.close()
+    themes, err = assets.LoadThemes(assetFS)
     if err != nil {
-        log.Fatalf("Failed to load goat.png: %v", err)
+        log.Printf("[Theme] %v", err)
     }
-    tigerTex, err = LoadTexture("tiger.png")
-    if err != nil {
-        log.Fatalf("Failed to load tiger.png: %v", err)
+    t := findTheme(*themeFlag)
+    if t == nil {
+        log.Printf("[Theme] Unknown theme %q, using the default", *themeFlag)
+        t = &defaultTheme
+    }
+    if err := applyTheme(t); err != nil {
+        log.Fatalln("Failed to load theme:", err)
     }
 
     // Set callbacks
     window.SetMouseButtonCallback(onMouseClick)
     window.SetCursorPosCallback(onMouseMove)
     window.SetKeyCallback(onKeyPress)
+    window.SetCharCallback(onChar)
+    window.SetScrollCallback(onScroll)
+
+    // Controllers, including ones plugged in later
+    initGamepads()
+
+    // ImGui panels, when built with -tags imgui
+    initGUI(window)
+
+    // The computer player, if one is set, then note the settings so that
+    // only what changes in game is saved
+    initAI()
+    startSettings()
+    defer saveSettings()
 
     // Main loop
     for !window.ShouldClose() {
-        gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
+        gfx.clear(theme.BackgroundColor)
+        now := glfw.GetTime()
+        updateAnimations(now)
+        updateClock(now)
+        updateAI()
+        updateGamepads(now)
+        updateReplayExport()
+        guiFrame()
+        drawBackground()
         drawBoard()
+        drawLastMove()
+        drawThreats()
+        drawHighlights()
         drawPieces()
+        drawAnimations(now)
+        drawKeyboardCursor()
         drawUI()
+        if moveListOpen {
+            drawMoveList()
+        }
 				if draggingPiece {
             drawDraggedPiece()
         }
 
-				if dialogActive {
-					drawDialogBox()
+				if settingsOpen {
+					drawSettingsMenu()
+				}
+				if dialogOpen() {
+					drawDialogs()
 			}
+        drawGUI()
 
+        gfx.flush()
         window.SwapBuffers()
         glfw.PollEvents()
     }
+    return 0
 }
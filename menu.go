@@ -0,0 +1,160 @@
+package main
+
+import (
+	"fmt"
+	"log"
+)
+
+// uiRect is a rectangle in NDC given by its top-left (x1, y1) and
+// bottom-right (x2, y2) corners, the form pointInRect expects.
+type uiRect = struct{ x1, y1, x2, y2 float32 }
+
+var (
+	settingsButtonRect = uiRect{0.75, 0.82, 0.95, 0.72}
+	settingsOpen       bool
+)
+
+// settingsToggles are the on/off rows below the themes.
+var settingsToggles = []struct {
+	label  func() string
+	toggle func()
+}{
+	{func() string {
+		if clickToMove {
+			return "Move pieces by clicking"
+		}
+		return "Move pieces by dragging"
+	}, toggleInputStyle},
+	{func() string {
+		if muted, _ := audio.mix.state(); muted {
+			return "Sound off"
+		}
+		return "Sound on"
+	}, toggleMute},
+	{func() string {
+		if _, musicOff := audio.mix.state(); musicOff {
+			return "Music off"
+		}
+		return "Music on"
+	}, toggleMusic},
+	{func() string {
+		return fmt.Sprintf("Volume: %.0f%%", audio.mix.getVolume()*100)
+	}, cycleVolume},
+	{func() string {
+		if boardView.coords {
+			return "Coordinates shown"
+		}
+		return "Coordinates hidden"
+	}, toggleCoords},
+	{func() string {
+		if threatOverlay {
+			return "Threats shown"
+		}
+		return "Threats hidden"
+	}, toggleThreats},
+	{func() string {
+		return "Board: " + orientations[boardTurns()]
+	}, func() { rotateBoard(1) }},
+	{func() string { return "Colours: " + currentPalette.Name }, cyclePalette},
+	{func() string {
+		return fmt.Sprintf("UI scale: %.0f%%", uiScaleValue*100)
+	}, cycleUIScale},
+	{func() string {
+		if ai.side == 0 {
+			return "Computer: off"
+		}
+		return "Computer plays " + sideName(ai.side)
+	}, cycleAISide},
+	{func() string { return "Level: " + ai.level }, cycleAILevel},
+	{func() string { return "Move time: " + ai.moveTime.String() }, cycleMoveTime},
+	{func() string { return "Clock: " + clock.control.String() }, cycleTimeControl},
+}
+
+// settingsLayout places the settings panel, one row per theme, the
+// toggles in two columns and the close button, centred on the board.
+func settingsLayout() (panel uiRect, rows, toggles []uiRect, closeButton uiRect) {
+	const rowH, gap = 0.1, 0.03
+	toggleRows := (len(settingsToggles) + 1) / 2
+	h := 0.3 + float32(len(themes)+toggleRows)*(rowH+gap) + rowH
+	top := h / 2
+	panel = uiRect{-0.8, top, 0.8, -top}
+	y := top - 0.2
+	for range themes {
+		rows = append(rows, uiRect{-0.35, y, 0.35, y - rowH})
+		y -= rowH + gap
+	}
+	for i := range settingsToggles {
+		x := float32(-0.7)
+		if i%2 == 1 {
+			x = 0.02
+		}
+		toggles = append(toggles, uiRect{x, y, x + 0.68, y - rowH})
+		if i%2 == 1 || i == len(settingsToggles)-1 {
+			y -= rowH + gap
+		}
+	}
+	closeButton = uiRect{-0.15, y - 0.02, 0.15, y - 0.02 - rowH}
+	return panel, rows, toggles, closeButton
+}
+
+// drawSettingsButton draws the button that opens the settings panel.
+func drawSettingsButton() {
+	r := settingsButtonRect
+	gfx.rect(r.x1, r.y1, r.x2, r.y2, [4]float32{0.2, 0.2, 0.2, 1})
+	drawButtonLabel(r.x1, r.y1, r.x2, r.y2, "SETTINGS", defaultText.color)
+}
+
+// drawSettingsMenu draws the settings panel: themes, then the toggles.
+func drawSettingsMenu() {
+	panel, rows, toggles, closeButton := settingsLayout()
+	dark := [4]float32{0.1, 0.1, 0.1, 1}
+
+	gfx.rect(-1, 1, 1, -1, [4]float32{0, 0, 0, 0.5})
+	gfx.rect(panel.x1, panel.y1, panel.x2, panel.y2, [4]float32{0.8, 0.8, 0.8, 1})
+	drawText(0, panel.y1-0.04, "Settings", textOptions{size: titleTextSize, align: alignCenter, color: dark})
+
+	for i, r := range rows {
+		c := [4]float32{0.65, 0.65, 0.65, 1}
+		if themes[i] == theme {
+			c = [4]float32{0.4, 0.8, 0.4, 1}
+		}
+		gfx.rect(r.x1, r.y1, r.x2, r.y2, c)
+		drawButtonLabel(r.x1, r.y1, r.x2, r.y2, themes[i].Name, dark)
+	}
+
+	for i, r := range toggles {
+		gfx.rect(r.x1, r.y1, r.x2, r.y2, [4]float32{0.4, 0.6, 0.9, 1})
+		drawButtonLabel(r.x1, r.y1, r.x2, r.y2, settingsToggles[i].label(), dark)
+	}
+
+	gfx.rect(closeButton.x1, closeButton.y1, closeButton.x2, closeButton.y2, [4]float32{0.8, 0.4, 0.4, 1})
+	drawButtonLabel(closeButton.x1, closeButton.y1, closeButton.x2, closeButton.y2, "Close", dark)
+}
+
+// settingsClick handles a click while the settings panel is open.
+func settingsClick(x, y float32) {
+	_, rows, toggles, closeButton := settingsLayout()
+	for i, r := range rows {
+		if pointInRect(x, y, r) {
+			if err := applyTheme(themes[i]); err != nil {
+				log.Printf("[Theme] %s: %v", themes[i].ID, err)
+			}
+			return
+		}
+	}
+	for i, r := range toggles {
+		if pointInRect(x, y, r) {
+			settingsToggles[i].toggle()
+			return
+		}
+	}
+	if pointInRect(x, y, closeButton) {
+		closeSettings()
+	}
+}
+
+// closeSettings closes the settings panel and saves what was changed.
+func closeSettings() {
+	settingsOpen = false
+	saveSettings()
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/baag_chal_gl/rules"
+)
+
+// The move list panel shows the game so far as goat/tiger pairs. Clicking a
+// move or stepping with the arrow keys shows the position after it; the
+// board is view-only until play resumes at the last move.
+
+// The panel is drawn beside the board, in a viewport the size of the
+// board's moved moveListShift to the right, so that its left edge at
+// moveListPanelX meets the board's right edge. applyLayout keeps
+// moveListReserve of the board's size free for it.
+const (
+	moveListPanelX  = 0.35
+	moveListShift   = 1 - moveListPanelX
+	moveListReserve = 0.33
+)
+
+var (
+	moveListButtonRect = uiRect{0.75, 0.69, 0.95, 0.59}
+	moveListOpen       bool
+
+	// viewPly is the number of moves played in the position on the board,
+	// or -1 while it shows the live game.
+	viewPly = -1
+)
+
+// viewing reports whether the board shows an earlier position.
+func viewing() bool {
+	return viewPly >= 0
+}
+
+// shownPosition is the position the board draws.
+func shownPosition() rules.Position {
+	if viewing() {
+		return history.PositionAt(viewPly)
+	}
+	return currentPosition()
+}
+
+// shownPly is the number of moves played in the shown position.
+func shownPly() int {
+	if viewing() {
+		return viewPly
+	}
+	return history.Ply()
+}
+
+// viewAt shows the position after ply moves. Reaching the last move
+// resumes the live game.
+func viewAt(ply int) {
+	if ply < 0 {
+		ply = 0
+	}
+	if ply >= history.Ply() {
+		resumeLive()
+		return
+	}
+	if !viewing() {
+		skipAnimations()
+		draggingPiece = false
+		clearSelection()
+	}
+	viewPly = ply
+}
+
+// stepView moves the shown position delta moves back or forth.
+func stepView(delta int) {
+	viewAt(shownPly() + delta)
+}
+
+// resumeLive returns the board to the game in progress.
+func resumeLive() {
+	viewPly = -1
+}
+
+// toggleMoveList opens or closes the move list and makes room for it.
+func toggleMoveList() {
+	moveListOpen = !moveListOpen
+	applyLayout()
+}
+
+// moveCell is one move in the list and where it is drawn.
+type moveCell struct {
+	rect uiRect
+	ply  int
+}
+
+// moveRowOf returns the row and column (0 goat, 1 tiger) of move i. A game
+// started with the tigers to move leaves the first goat cell empty.
+func moveRowOf(i int) (row, col int) {
+	if history.PositionAt(i).Turn == rules.Tiger {
+		col = 1
+	}
+	return history.MoveNumber(i) - 1, col
+}
+
+// moveListLayout places the panel with the start position, the visible
+// moves and the resume button, in the coordinates of the panel's viewport.
+// The list scrolls to keep the shown move in view.
+func moveListLayout() (panel uiRect, start moveCell, cells []moveCell, resume uiRect) {
+	const rowH = 0.07
+	panel = uiRect{moveListPanelX, 0.95, 0.98, -0.95}
+	top := panel.y1 - 0.13
+	start = moveCell{uiRect{panel.x1 + 0.02, top, panel.x2 - 0.02, top - rowH}, 0}
+	listTop := top - rowH
+	resume = uiRect{panel.x1 + 0.15, panel.y2 + 0.12, panel.x2 - 0.15, panel.y2 + 0.03}
+
+	visible := int((listTop - resume.y1 - 0.02) / rowH)
+	rows := 0
+	if n := history.Ply(); n > 0 {
+		last, _ := moveRowOf(n - 1)
+		rows = last + 1
+	}
+	sel := rows - 1
+	if viewing() && viewPly > 0 {
+		sel, _ = moveRowOf(viewPly - 1)
+	}
+	first := sel - visible + 1
+	if first < 0 {
+		first = 0
+	}
+
+	colX := [2]float32{panel.x1 + 0.14, panel.x1 + 0.38}
+	for i := range history.Moves {
+		row, col := moveRowOf(i)
+		if row < first || row >= first+visible {
+			continue
+		}
+		y := listTop - float32(row-first)*rowH
+		cells = append(cells, moveCell{uiRect{colX[col], y, colX[col] + 0.22, y - rowH}, i + 1})
+	}
+	return panel, start, cells, resume
+}
+
+// drawMoveListButton draws the button that opens the move list.
+func drawMoveListButton() {
+	r := moveListButtonRect
+	gfx.rect(r.x1, r.y1, r.x2, r.y2, [4]float32{0.2, 0.2, 0.2, 1})
+	drawButtonLabel(r.x1, r.y1, r.x2, r.y2, "MOVES", defaultText.color)
+}
+
+// drawMoveList draws the move list panel beside the board.
+func drawMoveList() {
+	gfx.flush()
+	shift := int(moveListShift / 2 * float64(view.vpSize))
+	gfx.viewport(int32(view.vpX+shift), int32(view.vpY), int32(view.vpSize), int32(view.vpSize))
+	defer func() {
+		gfx.flush()
+		gfx.viewport(int32(view.vpX), int32(view.vpY), int32(view.vpSize), int32(view.vpSize))
+	}()
+
+	panel, start, cells, resume := moveListLayout()
+	dark := [4]float32{0.1, 0.1, 0.1, 1}
+	selected := [4]float32{0.4, 0.8, 0.4, 1}
+
+	gfx.rect(panel.x1, panel.y1, panel.x2, panel.y2, [4]float32{0.8, 0.8, 0.8, 0.9})
+	drawText((panel.x1+panel.x2)/2, panel.y1-0.03, "Moves", textOptions{size: titleTextSize, align: alignCenter, color: dark})
+
+	cell := func(c moveCell, label string) {
+		if c.ply == shownPly() {
+			gfx.rect(c.rect.x1, c.rect.y1, c.rect.x2, c.rect.y2, selected)
+		}
+		_, h := measureText(label, uiTextSize)
+		drawText(c.rect.x1+0.02, (c.rect.y1+c.rect.y2)/2+h/2, label, textOptions{size: uiTextSize, color: dark})
+	}
+	cell(start, "Start")
+	for _, c := range cells {
+		i := c.ply - 1
+		if _, col := moveRowOf(i); col == 0 || i == 0 {
+			num := fmt.Sprintf("%d.", history.MoveNumber(i))
+			_, h := measureText(num, uiTextSize)
+			drawText(panel.x1+0.03, (c.rect.y1+c.rect.y2)/2+h/2, num, textOptions{size: uiTextSize, color: dark})
+		}
+		cell(c, history.Moves[i].String())
+	}
+
+	if viewing() {
+		gfx.rect(resume.x1, resume.y1, resume.x2, resume.y2, [4]float32{0.4, 0.6, 0.9, 1})
+		drawButtonLabel(resume.x1, resume.y1, resume.x2, resume.y2, "Resume", dark)
+	}
+}
+
+// moveListClick handles a click inside the move list panel, given in the
+// board's NDC. It reports whether the click was on the panel.
+func moveListClick(x, y float32) bool {
+	x -= moveListShift
+	panel, start, cells, resume := moveListLayout()
+	if !pointInRect(x, y, panel) {
+		return false
+	}
+	if viewing() && pointInRect(x, y, resume) {
+		resumeLive()
+		return true
+	}
+	for _, c := range append(cells, start) {
+		if pointInRect(x, y, c.rect) {
+			viewAt(c.ply)
+			break
+		}
+	}
+	return true
+}
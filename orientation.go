@@ -0,0 +1,116 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"strings"
+
+	"github.com/baag_chal_gl/rules"
+)
+
+// The board can be turned in quarter turns and labelled with its files and
+// ranks. Everything that places things on the board goes through
+// boardPoint and screenToBoardCoords, which apply the turn; the rules and
+// the keyboard cursor keep working in board coordinates.
+
+var (
+	orientFlag = flag.String("orient", "normal", "board orientation: normal, flipped, left, right, or auto to face the human player's side")
+	coordsFlag = flag.Bool("coords", true, "label the board's files and ranks")
+)
+
+var boardView = struct {
+	// turns is the number of quarter turns clockwise
+	turns int
+	// auto flips the board when the computer plays the goats, so the
+	// player sees the board from the tigers' side
+	auto   bool
+	coords bool
+}{coords: true}
+
+// orientations are the flag values by number of quarter turns.
+var orientations = []string{"normal", "right", "flipped", "left"}
+
+// initOrientation applies the -orient and -coords flags.
+func initOrientation() {
+	boardView.coords = *coordsFlag
+	name := strings.ToLower(*orientFlag)
+	if name == "auto" {
+		boardView.auto = true
+		return
+	}
+	for i, o := range orientations {
+		if o == name {
+			boardView.turns = i
+			return
+		}
+	}
+	log.Printf("[Board] Unknown orientation %q, using normal", *orientFlag)
+}
+
+// boardTurns is the rotation in effect.
+func boardTurns() int {
+	if boardView.auto && ai.side == rules.Goat {
+		return 2
+	}
+	return boardView.turns
+}
+
+// rotateBoard turns the board by quarter turns clockwise. It ends the
+// automatic orientation.
+func rotateBoard(quarters int) {
+	boardView.turns = ((boardTurns()+quarters)%4 + 4) % 4
+	boardView.auto = false
+	skipAnimations()
+	log.Printf("[Board] Orientation: %s", orientations[boardView.turns])
+	announce("Board " + orientations[boardView.turns] + ".")
+}
+
+// toggleCoords shows or hides the file and rank labels.
+func toggleCoords() {
+	boardView.coords = !boardView.coords
+}
+
+// turnPoint rotates a board point by quarter turns clockwise.
+func turnPoint(p [2]int, turns int) [2]int {
+	for range (turns%4 + 4) % 4 {
+		p = [2]int{p[1], rules.Size - 1 - p[0]}
+	}
+	return p
+}
+
+// turnDelta rotates a step between points by quarter turns clockwise.
+func turnDelta(d [2]int, turns int) [2]int {
+	for range (turns%4 + 4) % 4 {
+		d = [2]int{d[1], -d[0]}
+	}
+	return d
+}
+
+// gridPoint is the NDC centre of a point of the grid as displayed.
+func gridPoint(p [2]int) [2]float32 {
+	return [2]float32{boardPosX(p[0]), boardPosY(p[1])}
+}
+
+// drawCoords labels the files and ranks along the bottom and left edges.
+// With the board turned a quarter, the ranks run along the bottom.
+func drawCoords() {
+	if !boardView.coords {
+		return
+	}
+	opts := textOptions{size: uiTextSize, align: alignCenter, color: theme.TextColor}
+	_, h := measureText("a", uiTextSize)
+	back := 4 - boardTurns()
+	// Each label is the coordinate that changes along its edge
+	bottomFile := turnPoint([2]int{0, 0}, back)[0] != turnPoint([2]int{1, 0}, back)[0]
+	for i := 0; i < rules.Size; i++ {
+		bottom := rules.PointString(turnPoint([2]int{i, 0}, back))
+		left := rules.PointString(turnPoint([2]int{0, i}, back))
+		if bottomFile {
+			bottom, left = bottom[:1], left[1:]
+		} else {
+			bottom, left = bottom[1:], left[:1]
+		}
+		drawText(boardPosX(i), -0.88+h/2, bottom, opts)
+		drawText(-0.9, boardPosY(i)+h/2, left, opts)
+	}
+}
@@ -0,0 +1,59 @@
+package main
+
+import "flag"
+
+// The last move is always marked: where the piece came from, where it went
+// and, for a capture, where the goat was taken. The threat overlay adds the
+// goats a tiger can capture next and the tigers that cannot move at all.
+
+var threatsFlag = flag.Bool("threats", false, "mark goats that can be captured and trapped tigers")
+
+var threatOverlay bool
+
+// toggleThreats turns the threat overlay on or off.
+func toggleThreats() {
+	threatOverlay = !threatOverlay
+}
+
+// drawLastMove marks the move that led to the shown position.
+func drawLastMove() {
+	ply := shownPly()
+	if ply == 0 {
+		return
+	}
+	m := history.Moves[ply-1]
+	c := highlightColors().LastMove
+	if !m.IsPlacement() {
+		from := boardPoint(m.From)
+		drawRing(from[0], from[1], 0.06, 3, c)
+	}
+	to := boardPoint(m.To)
+	drawCircle(to[0], to[1], 0.085, 32, c)
+	if m.IsJump() {
+		// A cross where the goat was
+		at := boardPoint(m.Captured())
+		const s = 0.04
+		width := 4 * view.contentScale * uiScale()
+		threat := highlightColors().Threat
+		gfx.line(at[0]-s, at[1]-s, at[0]+s, at[1]+s, width, threat)
+		gfx.line(at[0]-s, at[1]+s, at[0]+s, at[1]-s, width, threat)
+	}
+}
+
+// drawThreats marks the goats under attack and the trapped tigers in the
+// shown position.
+func drawThreats() {
+	if !threatOverlay {
+		return
+	}
+	p := shownPosition()
+	c := highlightColors().Threat
+	for _, pt := range p.ThreatenedGoats() {
+		at := boardPoint(pt)
+		drawCircle(at[0], at[1], 0.075, 32, c)
+	}
+	for _, pt := range p.TrappedTigers() {
+		at := boardPoint(pt)
+		drawRing(at[0], at[1], 0.095, 4, c)
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"image"
+
+	"github.com/go-gl/gl/v2.1/gl"
+)
+
+// legacyBackend is the fixed-function OpenGL 2.1 path for old drivers. It
+// draws the same batches in immediate mode; lines are not anti-aliased.
+type legacyBackend struct{}
+
+func (legacyBackend) name() string { return "OpenGL 2.1" }
+
+func (legacyBackend) setup() error {
+	if err := gl.Init(); err != nil {
+		return err
+	}
+	gl.Enable(gl.BLEND)
+	gl.BlendFunc(gl.SRC_ALPHA, gl.ONE_MINUS_SRC_ALPHA)
+	gl.Enable(gl.TEXTURE_2D)
+	return nil
+}
+
+func (legacyBackend) viewport(x, y, w, h int32) { gl.Viewport(x, y, w, h) }
+
+func (legacyBackend) clear(c [4]float32) {
+	gl.ClearColor(c[0], c[1], c[2], c[3])
+	gl.Clear(gl.COLOR_BUFFER_BIT)
+}
+
+func (legacyBackend) newTexture(rgba *image.RGBA) uint32 {
+	var texture uint32
+	gl.GenTextures(1, &texture)
+	gl.BindTexture(gl.TEXTURE_2D, texture)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, gl.LINEAR)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.LINEAR)
+	gl.TexImage2D(gl.TEXTURE_2D, 0, gl.RGBA,
+		int32(rgba.Bounds().Dx()), int32(rgba.Bounds().Dy()), 0,
+		gl.RGBA, gl.UNSIGNED_BYTE, gl.Ptr(rgba.Pix))
+	gl.BindTexture(gl.TEXTURE_2D, 0)
+	return texture
+}
+
+func (legacyBackend) drawTriangles(tex uint32, verts []vertex) {
+	gl.BindTexture(gl.TEXTURE_2D, tex)
+	gl.Begin(gl.TRIANGLES)
+	for _, v := range verts {
+		gl.Color4f(v.color[0], v.color[1], v.color[2], v.color[3])
+		gl.TexCoord2f(v.u, v.v)
+		gl.Vertex2f(v.x, v.y)
+	}
+	gl.End()
+	gl.BindTexture(gl.TEXTURE_2D, 0)
+	gl.Color4f(1, 1, 1, 1)
+}
+
+func (legacyBackend) scissor(r image.Rectangle) {
+	if r.Empty() {
+		gl.Disable(gl.SCISSOR_TEST)
+		return
+	}
+	gl.Enable(gl.SCISSOR_TEST)
+	gl.Scissor(int32(r.Min.X), int32(r.Min.Y), int32(r.Dx()), int32(r.Dy()))
+}
+
+func (legacyBackend) smoothLines() bool { return false }
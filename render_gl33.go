@@ -0,0 +1,189 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"strings"
+	"unsafe"
+
+	"github.com/go-gl/gl/v3.3-core/gl"
+)
+
+const spriteVertexShader = `#version 330 core
+layout(location = 0) in vec2 aPos;
+layout(location = 1) in vec2 aUV;
+layout(location = 2) in vec4 aColor;
+layout(location = 3) in vec2 aEdge;
+out vec2 vUV;
+out vec4 vColor;
+out vec2 vEdge;
+void main() {
+	vUV = aUV;
+	vColor = aColor;
+	vEdge = aEdge;
+	gl_Position = vec4(aPos, 0.0, 1.0);
+}
+` + "\x00"
+
+// The fragment shader fades line edges over the last pixel using the
+// interpolated distance from the centre line.
+const spriteFragmentShader = `#version 330 core
+in vec2 vUV;
+in vec4 vColor;
+in vec2 vEdge;
+uniform sampler2D uTex;
+out vec4 fragColor;
+void main() {
+	vec4 c = texture(uTex, vUV) * vColor;
+	if (vEdge.y > 0.0) {
+		c.a *= clamp(vEdge.y - abs(vEdge.x) + 0.5, 0.0, 1.0);
+	}
+	fragColor = c;
+}
+` + "\x00"
+
+// coreBackend renders through a single shader program and a streamed
+// vertex buffer on an OpenGL 3.3 core context.
+type coreBackend struct {
+	program uint32
+	vao     uint32
+	vbo     uint32
+}
+
+func (*coreBackend) name() string { return "OpenGL 3.3 core" }
+
+func (b *coreBackend) setup() error {
+	if err := gl.Init(); err != nil {
+		return err
+	}
+	program, err := linkProgram(spriteVertexShader, spriteFragmentShader)
+	if err != nil {
+		return err
+	}
+	b.program = program
+	gl.UseProgram(program)
+	gl.Uniform1i(gl.GetUniformLocation(program, gl.Str("uTex\x00")), 0)
+
+	gl.GenVertexArrays(1, &b.vao)
+	gl.BindVertexArray(b.vao)
+	gl.GenBuffers(1, &b.vbo)
+	gl.BindBuffer(gl.ARRAY_BUFFER, b.vbo)
+
+	stride := int32(unsafe.Sizeof(vertex{}))
+	attribs := []struct {
+		size   int32
+		offset uintptr
+	}{
+		{2, unsafe.Offsetof(vertex{}.x)},
+		{2, unsafe.Offsetof(vertex{}.u)},
+		{4, unsafe.Offsetof(vertex{}.color)},
+		{2, unsafe.Offsetof(vertex{}.edge)},
+	}
+	for i, a := range attribs {
+		gl.EnableVertexAttribArray(uint32(i))
+		gl.VertexAttribPointerWithOffset(uint32(i), a.size, gl.FLOAT, false, stride, a.offset)
+	}
+
+	gl.Enable(gl.BLEND)
+	gl.BlendFunc(gl.SRC_ALPHA, gl.ONE_MINUS_SRC_ALPHA)
+	return nil
+}
+
+func (*coreBackend) viewport(x, y, w, h int32) { gl.Viewport(x, y, w, h) }
+
+func (*coreBackend) clear(c [4]float32) {
+	gl.ClearColor(c[0], c[1], c[2], c[3])
+	gl.Clear(gl.COLOR_BUFFER_BIT)
+}
+
+func (*coreBackend) newTexture(rgba *image.RGBA) uint32 {
+	var texture uint32
+	gl.GenTextures(1, &texture)
+	gl.BindTexture(gl.TEXTURE_2D, texture)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, gl.LINEAR)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.LINEAR)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, gl.CLAMP_TO_EDGE)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, gl.CLAMP_TO_EDGE)
+	gl.PixelStorei(gl.UNPACK_ALIGNMENT, 1)
+	gl.TexImage2D(gl.TEXTURE_2D, 0, gl.RGBA,
+		int32(rgba.Bounds().Dx()), int32(rgba.Bounds().Dy()), 0,
+		gl.RGBA, gl.UNSIGNED_BYTE, gl.Ptr(rgba.Pix))
+	gl.BindTexture(gl.TEXTURE_2D, 0)
+	return texture
+}
+
+func (b *coreBackend) drawTriangles(tex uint32, verts []vertex) {
+	gl.UseProgram(b.program)
+	gl.BindVertexArray(b.vao)
+	gl.BindBuffer(gl.ARRAY_BUFFER, b.vbo)
+	// Orphan and refill the buffer each batch so the driver never stalls
+	// on a buffer the GPU is still reading.
+	size := len(verts) * int(unsafe.Sizeof(vertex{}))
+	gl.BufferData(gl.ARRAY_BUFFER, size, gl.Ptr(verts), gl.STREAM_DRAW)
+
+	gl.ActiveTexture(gl.TEXTURE0)
+	gl.BindTexture(gl.TEXTURE_2D, tex)
+	gl.DrawArrays(gl.TRIANGLES, 0, int32(len(verts)))
+}
+
+func (*coreBackend) scissor(r image.Rectangle) {
+	if r.Empty() {
+		gl.Disable(gl.SCISSOR_TEST)
+		return
+	}
+	gl.Enable(gl.SCISSOR_TEST)
+	gl.Scissor(int32(r.Min.X), int32(r.Min.Y), int32(r.Dx()), int32(r.Dy()))
+}
+
+func (*coreBackend) smoothLines() bool { return true }
+
+// linkProgram compiles and links a vertex/fragment shader pair.
+func linkProgram(vertexSrc, fragmentSrc string) (uint32, error) {
+	vs, err := compileShader(vertexSrc, gl.VERTEX_SHADER)
+	if err != nil {
+		return 0, err
+	}
+	defer gl.DeleteShader(vs)
+	fs, err := compileShader(fragmentSrc, gl.FRAGMENT_SHADER)
+	if err != nil {
+		return 0, err
+	}
+	defer gl.DeleteShader(fs)
+
+	program := gl.CreateProgram()
+	gl.AttachShader(program, vs)
+	gl.AttachShader(program, fs)
+	gl.LinkProgram(program)
+
+	var status int32
+	gl.GetProgramiv(program, gl.LINK_STATUS, &status)
+	if status == gl.FALSE {
+		var n int32
+		gl.GetProgramiv(program, gl.INFO_LOG_LENGTH, &n)
+		msg := strings.Repeat("\x00", int(n+1))
+		gl.GetProgramInfoLog(program, n, nil, gl.Str(msg))
+		gl.DeleteProgram(program)
+		return 0, fmt.Errorf("link shader program: %s", strings.TrimRight(msg, "\x00"))
+	}
+	return program, nil
+}
+
+func compileShader(src string, kind uint32) (uint32, error) {
+	shader := gl.CreateShader(kind)
+	csrc, free := gl.Strs(src)
+	gl.ShaderSource(shader, 1, csrc, nil)
+	free()
+	gl.CompileShader(shader)
+
+	var status int32
+	gl.GetShaderiv(shader, gl.COMPILE_STATUS, &status)
+	if status == gl.FALSE {
+		var n int32
+		gl.GetShaderiv(shader, gl.INFO_LOG_LENGTH, &n)
+		msg := strings.Repeat("\x00", int(n+1))
+		gl.GetShaderInfoLog(shader, n, nil, gl.Str(msg))
+		gl.DeleteShader(shader)
+		return 0, fmt.Errorf("compile shader: %s", strings.TrimRight(msg, "\x00"))
+	}
+	return shader, nil
+}
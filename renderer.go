@@ -0,0 +1,206 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"image"
+	"log"
+	"math"
+
+	"github.com/go-gl/glfw/v3.3/glfw"
+)
+
+var rendererFlag = flag.String("renderer", "auto", "OpenGL backend: auto, gl33 or gl21")
+
+// vertex is the single vertex format shared by both backends. Positions are
+// NDC, uv are texture coordinates with v=0 at the top of the image. edge is
+// only used for anti-aliased lines: the signed distance from the centre line
+// and the half width, both in pixels; a zero half width disables it.
+type vertex struct {
+	x, y  float32
+	u, v  float32
+	color [4]float32
+	edge  [2]float32
+}
+
+// backend is an OpenGL code path. Drawing is always submitted as batches of
+// textured triangles.
+type backend interface {
+	name() string
+	// setup loads GL entry points and creates GL objects; the context must
+	// be current.
+	setup() error
+	viewport(x, y, w, h int32)
+	clear(c [4]float32)
+	newTexture(rgba *image.RGBA) uint32
+	drawTriangles(tex uint32, verts []vertex)
+	// scissor limits drawing to r in framebuffer pixels with the origin at
+	// the bottom-left; an empty r turns clipping off.
+	scissor(r image.Rectangle)
+	// smoothLines reports whether the edge attribute is honoured.
+	smoothLines() bool
+}
+
+// renderer batches geometry by texture and hands it to the backend. All
+// drawing in the game goes through gfx.
+type renderer struct {
+	backend
+	white uint32
+	tex   uint32
+	verts []vertex
+}
+
+var gfx *renderer
+
+func newRenderer(b backend) (*renderer, error) {
+	if err := b.setup(); err != nil {
+		return nil, err
+	}
+	r := &renderer{backend: b}
+	white := image.NewRGBA(image.Rect(0, 0, 1, 1))
+	white.Pix[0], white.Pix[1], white.Pix[2], white.Pix[3] = 255, 255, 255, 255
+	r.white = b.newTexture(white)
+	return r, nil
+}
+
+// use switches the batch to tex, flushing pending geometry if needed.
+func (r *renderer) use(tex uint32) {
+	if tex != r.tex && len(r.verts) > 0 {
+		r.flush()
+	}
+	r.tex = tex
+}
+
+// flush draws everything batched so far.
+func (r *renderer) flush() {
+	if len(r.verts) == 0 {
+		return
+	}
+	r.drawTriangles(r.tex, r.verts)
+	r.verts = r.verts[:0]
+}
+
+// quad appends two triangles for the corners given in order around the quad.
+func (r *renderer) quad(a, b, c, d vertex) {
+	r.verts = append(r.verts, a, b, c, a, c, d)
+}
+
+// sprite draws tex (or the part of it given by u0,v0..u1,v1) into the
+// rectangle with top-left corner (x1, y1) and bottom-right (x2, y2).
+func (r *renderer) sprite(tex uint32, x1, y1, x2, y2, u0, v0, u1, v1 float32, c [4]float32) {
+	r.use(tex)
+	r.quad(
+		vertex{x: x1, y: y1, u: u0, v: v0, color: c},
+		vertex{x: x2, y: y1, u: u1, v: v0, color: c},
+		vertex{x: x2, y: y2, u: u1, v: v1, color: c},
+		vertex{x: x1, y: y2, u: u0, v: v1, color: c},
+	)
+}
+
+// rect fills an axis-aligned rectangle.
+func (r *renderer) rect(x1, y1, x2, y2 float32, c [4]float32) {
+	r.sprite(r.white, x1, y1, x2, y2, 0, 0, 1, 1, c)
+}
+
+// circle fills a circle of the given radius in NDC.
+func (r *renderer) circle(x, y, radius float32, segments int, c [4]float32) {
+	r.use(r.white)
+	step := 2 * math.Pi / float64(segments)
+	centre := vertex{x: x, y: y, color: c}
+	prev := vertex{x: x + radius, y: y, color: c}
+	for i := 1; i <= segments; i++ {
+		theta := step * float64(i)
+		next := vertex{
+			x:     x + radius*float32(math.Cos(theta)),
+			y:     y + radius*float32(math.Sin(theta)),
+			color: c,
+		}
+		r.verts = append(r.verts, centre, prev, next)
+		prev = next
+	}
+}
+
+// line draws a segment width pixels thick with square caps. On backends
+// with smooth lines the quad gets a one pixel feather that the fragment
+// shader fades out.
+func (r *renderer) line(x1, y1, x2, y2, width float32, c [4]float32) {
+	sx, _ := pixelsToNDC(1, 1)
+	if sx == 0 {
+		return
+	}
+	// Work in pixels so the feather is the same on every axis.
+	dx, dy := (x2-x1)/sx, (y2-y1)/sx
+	length := float32(math.Hypot(float64(dx), float64(dy)))
+	if length == 0 {
+		return
+	}
+	half := width / 2
+	outer := half
+	if r.smoothLines() {
+		outer++
+	}
+	// Unit direction and normal, scaled back to NDC.
+	ux, uy := dx/length, dy/length
+	ax, ay := ux*half*sx, uy*half*sx
+	nx, ny := -uy*outer*sx, ux*outer*sx
+
+	edge := func(d float32) [2]float32 { return [2]float32{d, half} }
+	r.use(r.white)
+	r.quad(
+		vertex{x: x1 - ax + nx, y: y1 - ay + ny, color: c, edge: edge(outer)},
+		vertex{x: x2 + ax + nx, y: y2 + ay + ny, color: c, edge: edge(outer)},
+		vertex{x: x2 + ax - nx, y: y2 + ay - ny, color: c, edge: edge(-outer)},
+		vertex{x: x1 - ax - nx, y: y1 - ay - ny, color: c, edge: edge(-outer)},
+	)
+}
+
+// openWindow creates the game window on the requested backend. With "auto"
+// it tries an OpenGL 3.3 core context first and falls back to the 2.1 path
+// when the driver cannot create one or the shaders fail to build.
+func openWindow(width, height int, title, want string) (*glfw.Window, *renderer, error) {
+	var candidates []string
+	switch want {
+	case "auto":
+		candidates = []string{"gl33", "gl21"}
+	case "gl33", "gl21":
+		candidates = []string{want}
+	default:
+		return nil, nil, fmt.Errorf("unknown renderer %q", want)
+	}
+
+	var lastErr error
+	for _, c := range candidates {
+		glfw.DefaultWindowHints()
+		glfw.WindowHint(glfw.Resizable, glfw.True)
+		glfw.WindowHint(glfw.ScaleToMonitor, glfw.True)
+		glfw.WindowHint(glfw.CocoaRetinaFramebuffer, glfw.True)
+		var b backend
+		if c == "gl33" {
+			glfw.WindowHint(glfw.ContextVersionMajor, 3)
+			glfw.WindowHint(glfw.ContextVersionMinor, 3)
+			glfw.WindowHint(glfw.OpenGLProfile, glfw.OpenGLCoreProfile)
+			glfw.WindowHint(glfw.OpenGLForwardCompatible, glfw.True)
+			b = &coreBackend{}
+		} else {
+			b = &legacyBackend{}
+		}
+
+		window, err := glfw.CreateWindow(width, height, title, nil, nil)
+		if err != nil {
+			lastErr = err
+			log.Printf("Renderer %s unavailable: %v", b.name(), err)
+			continue
+		}
+		window.MakeContextCurrent()
+		r, err := newRenderer(b)
+		if err != nil {
+			lastErr = err
+			log.Printf("Renderer %s unavailable: %v", b.name(), err)
+			window.Destroy()
+			continue
+		}
+		log.Printf("Using %s renderer", b.name())
+		return window, r, nil
+	}
+	return nil, nil, lastErr
+}
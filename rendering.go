@@ -4,26 +4,16 @@ import (
 	"log"
 	"math"
 
-	"github.com/go-gl/gl/v2.1/gl"
+	"github.com/baag_chal_gl/diagram"
 )
 
-// resetGame re-initializes the entire board, placing tigers in the corners, etc.
+// resetGame re-initializes the entire board from the start position.
 func resetGame() {
-  // Clear board
-  for i := 0; i < 5; i++ {
-      for j := 0; j < 5; j++ {
-          boardState[i][j] = 0
-      }
-  }
-  // Place tigers in corners
-  boardState[0][0] = 2
-  boardState[0][4] = 2
-  boardState[4][0] = 2
-  boardState[4][4] = 2
-
-  turn = 1
-  placedGoats = 0
-  capturedGoats = 0
+  // Back to the start position, normally the tigers in the corners
+  stopAI()
+  startGame(startPosition)
+
+  clearAnimations()
   draggingPiece = false
   selectedPiece = [2]int{-1, -1}
   currentDragPos = [2]float32{0.0, 0.0}
@@ -40,86 +30,87 @@ func isOverResetButton(mouseX, mouseY float64) bool {
         ndcY >= resetButtonRect.minY && ndcY <= resetButtonRect.maxY
 }
 
-// drawBoard renders the 5x5 grid plus diagonal lines.
+// drawBoard renders the 5x5 grid plus diagonal lines, and the file and
+// rank labels if they are on. The lines look the same however the board
+// is turned.
 func drawBoard() {
-    gl.LineWidth(2.0)
-    gl.Color3f(1.0, 1.0, 1.0) // White lines
-
-    // 5 vertical + 5 horizontal lines
-    for i := 0; i < 5; i++ {
-        // Horizontal
-        startX := float32(-0.8)
-        startY := float32(-0.8 + 0.4*float32(i))
-        endX := float32(0.8)
-        endY := startY
-        gl.Begin(gl.LINES)
-        gl.Vertex2f(startX, startY)
-        gl.Vertex2f(endX, endY)
-        gl.End()
-
-        // Vertical
-        startX = float32(-0.8 + 0.4*float32(i))
-        startY = float32(-0.8)
-        endX = startX
-        endY = float32(0.8)
-        gl.Begin(gl.LINES)
-        gl.Vertex2f(startX, startY)
-        gl.Vertex2f(endX, endY)
-        gl.End()
+    width := theme.LineWidth * view.contentScale * uiScale()
+    for _, l := range diagram.BoardLines() {
+        gfx.line(l[0], l[1], l[2], l[3], width, theme.LineColor)
     }
+    drawCoords()
+}
 
-    // Some diagonals (traditional Baag-Chal has specific diagonals):
-    gl.Begin(gl.LINES)
-    // Main diagonal
-    gl.Vertex2f(-0.8, 0.8)
-    gl.Vertex2f(0.8, -0.8)
-    // Opposite diagonal
-    gl.Vertex2f(-0.8, -0.8)
-    gl.Vertex2f(0.8, 0.8)
-    // Extra diagonals to center points
-    gl.Vertex2f(0.0, 0.8)
-    gl.Vertex2f(-0.8, 0.0)
-    gl.Vertex2f(0.0, 0.8)
-    gl.Vertex2f(0.8, 0.0)
-    gl.Vertex2f(0.0, -0.8)
-    gl.Vertex2f(-0.8, 0.0)
-    gl.Vertex2f(0.0, -0.8)
-    gl.Vertex2f(0.8, 0.0)
-    gl.End()
+// drawHighlights marks the piece being dragged or selected and the points
+// it can move to.
+func drawHighlights() {
+    if !(draggingPiece || pieceSelected) || selectedPiece[0] < 0 {
+        return
+    }
+    c := highlightColors().Highlight
+    p := boardPoint(selectedPiece)
+    drawCircle(p[0], p[1], 0.075, 32, c)
+    pos := currentPosition()
+    for _, m := range pos.LegalMoves() {
+        if m.From == selectedPiece {
+            t := boardPoint(m.To)
+            drawCircle(t[0], t[1], 0.03, 16, c)
+        }
+    }
 }
 
+// drawPieceTex draws a square sprite of the given size centred on (x, y).
 func drawPieceTex(x, y float32, tex uint32, size float32) {
-  gl.BindTexture(gl.TEXTURE_2D, tex)
+  drawPieceTint(x, y, tex, size, [4]float32{1, 1, 1, 1})
+}
 
+// drawPieceTint draws a piece sprite multiplied by colour c.
+func drawPieceTint(x, y float32, tex uint32, size float32, c [4]float32) {
   half := size * 0.5
+  gfx.sprite(tex, x-half, y+half, x+half, y-half, 0, 0, 1, 1, c)
+}
 
-  gl.Begin(gl.QUADS)
-
-  // Top-left
-  gl.TexCoord2f(0, 0) // Flip vertically
-  gl.Vertex2f(x-half, y+half)
-
-  // Top-right
-  gl.TexCoord2f(1, 0) // Flip vertically
-  gl.Vertex2f(x+half, y+half)
-
-  // Bottom-right
-  gl.TexCoord2f(1, 1) // Flip vertically
-  gl.Vertex2f(x+half, y-half)
-
-  // Bottom-left
-  gl.TexCoord2f(0, 1) // Flip vertically
-  gl.Vertex2f(x-half, y-half)
-  gl.End()
+// drawPiece draws a goat or tiger at (x, y) scaled by scale.
+func drawPiece(piece int, x, y, scale float32, c [4]float32) {
+  switch piece {
+  case 1: // goat
+    if goatTex == 0 {
+      drawFallbackPiece(x, y, 0.12*scale, [4]float32{0.85, 0.85, 0.8, 1}, "G", c)
+      return
+    }
+    drawPieceTint(x, y, goatTex, 0.12*scale, c)
+  case 2: // tiger
+    if tigerTex == 0 {
+      drawFallbackPiece(x, y, 0.15*scale, [4]float32{0.95, 0.55, 0.1, 1}, "T", c)
+      return
+    }
+    drawPieceTint(x, y, tigerTex, 0.15*scale, c)
+  }
+}
 
-  gl.BindTexture(gl.TEXTURE_2D, 0)
+// drawFallbackPiece stands in for missing piece art: an outlined disc in
+// the piece colour with its initial on top.
+func drawFallbackPiece(x, y, size float32, base [4]float32, label string, tint [4]float32) {
+  fill := base
+  for i := range fill {
+    fill[i] *= tint[i]
+  }
+  outline := [4]float32{0.1, 0.1, 0.1, tint[3]}
+  drawCircle(x, y, size*0.5, 32, outline)
+  drawCircle(x, y, size*0.42, 32, fill)
+  if size > 0.06 {
+    _, h := measureText(label, uiTextSize)
+    drawText(x, y+h/2, label, textOptions{size: uiTextSize, align: alignCenter, color: outline})
+  }
 }
 
-// drawPieces renders goats and tigers on the board.
+// drawPieces renders goats and tigers on the board, or the earlier
+// position picked in the move list.
 func drawPieces() {
+    board := shownPosition().Board
     for i := 0; i < 5; i++ {
         for j := 0; j < 5; j++ {
-            piece := boardState[i][j]
+            piece := board[i][j]
             if piece == 0 {
                 continue
             }
@@ -127,47 +118,29 @@ func drawPieces() {
                 // Skip the piece that is currently being dragged
                 continue
             }
-            x := boardPosX(i)
-            y := boardPosY(j)
-
-            switch piece {
-            case 1: // goat
-                drawPieceTex(x, y, goatTex, 0.12)
-            case 2: // tiger
-                drawPieceTex(x, y, tigerTex, 0.15)
+            if hiddenByAnimation(i, j) {
+                // Drawn by drawAnimations while it moves into place
+                continue
             }
+            at := boardPoint([2]int{i, j})
+            drawPiece(piece, at[0], at[1], 1, white)
         }
     }
 }
 
 // drawDraggedPiece draws the piece under the mouse cursor
 func drawDraggedPiece() {
-    if turn == 1 {
-        // gl.Color3f(0.0, 1.0, 0.0) // Goat color
-        // drawCircle(currentDragPos[0], currentDragPos[1], goatRadius, 20)
-        drawPieceTex(currentDragPos[0], currentDragPos[1], goatTex, 0.12)
-    } else {
-        // gl.Color3f(1.0, 0.0, 0.0) // Tiger color
-        // drawCircle(currentDragPos[0], currentDragPos[1], tigerRadius, 20)
-        drawPieceTex(currentDragPos[0], currentDragPos[1], tigerTex, 0.15)
-    }
+    drawPiece(turn, currentDragPos[0], currentDragPos[1], 1, white)
 }
 
 // drawCircle draws a filled circle at (x, y).
-func drawCircle(x, y, radius float32, segments int) {
-    angleStep := float32(2.0*math.Pi) / float32(segments)
-    gl.Begin(gl.POLYGON)
-    for i := 0; i < segments; i++ {
-        theta := angleStep * float32(i)
-        px := x + radius*float32(math.Cos(float64(theta)))
-        py := y + radius*float32(math.Sin(float64(theta)))
-        gl.Vertex2f(px, py)
-    }
-    gl.End()
+func drawCircle(x, y, radius float32, segments int, c [4]float32) {
+    gfx.circle(x, y, radius, segments, c)
 }
 
 
-// screenToBoardCoords converts window coords to a board cell
+// screenToBoardCoords converts window coords to a board cell, undoing
+// the board's orientation
 func screenToBoardCoords(x, y float64) (int, int) {
 	ndcX, ndcY := screenToNDC(x, y)
 	// Each grid cell is 0.4 wide from -0.8..+0.8
@@ -178,17 +151,29 @@ func screenToBoardCoords(x, y float64) (int, int) {
 					cy := boardPosY(j)
 					if math.Abs(float64(cx)-float64(ndcX)) < 0.2 &&
 							math.Abs(float64(cy)-float64(ndcY)) < 0.2 {
-							return i, j
+							p := turnPoint([2]int{i, j}, 4-boardTurns())
+							return p[0], p[1]
 					}
 			}
 	}
 	return -1, -1
 }
 
-// screenToNDC converts window mouse coords to normalized device coords (-1..1)
+// screenToNDC converts window mouse coords to normalized device coords
+// (-1..1) of the square board viewport, using the live window layout
 func screenToNDC(x, y float64) (float32, float32) {
-	ndcX := float32((x / windowWidth) * 2 - 1)
-	ndcY := float32(1 - (y / windowHeight) * 2)
+	if view.winW == 0 || view.winH == 0 {
+		return -2, -2
+	}
+	// Cursor positions are in screen coordinates; the viewport is in pixels
+	px := x * float64(view.fbW) / float64(view.winW)
+	py := y * float64(view.fbH) / float64(view.winH)
+
+	size := float64(view.vpSize)
+	left := float64(view.vpX)
+	top := float64(view.fbH - view.vpY - view.vpSize)
+	ndcX := float32((px-left)/size*2 - 1)
+	ndcY := float32(1 - (py-top)/size*2)
 	return ndcX, ndcY
 }
 
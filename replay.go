@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/baag_chal_gl/diagram"
+	"github.com/baag_chal_gl/rules"
+)
+
+// runReplay is the replay command: it turns a game record into an
+// animated GIF or APNG. The drawing and encoding are done by the diagram
+// package, which cmd/replay uses without the game's cgo dependencies.
+//
+//	baag_chal_gl replay -o game.gif game.txt
+func runReplay(args []string) int {
+	fs := newFlagSet("replay", "record-file|-", "theme", "assets")
+	var cmd diagram.ReplayCommand
+	cmd.SetFlags(fs)
+	fs.Parse(args)
+	if fs.NArg() != 1 || cmd.Size < 16 || cmd.Delay <= 0 {
+		fs.Usage()
+		return 2
+	}
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+	loadSettings(fs)
+	initAssets()
+
+	if err := cmd.Run(assetFS, *themeFlag, fs.Arg(0)); err != nil {
+		fmt.Fprintln(os.Stderr, "replay:", err)
+		return 1
+	}
+	return 0
+}
+
+// replayExport is the in-game export running in the background; done
+// receives the file name or the error.
+var replayExport struct {
+	running bool
+	done    chan error
+	file    string
+}
+
+// exportRenderer draws in-game exports, keeping its caches between them.
+var exportRenderer = sync.OnceValue(func() *diagram.Renderer {
+	return diagram.NewRenderer(assetFS)
+})
+
+// exportReplay saves the game so far as a GIF in the working directory.
+// Rendering takes a moment, so it runs off the main thread on a copy of
+// the game.
+func exportReplay() {
+	if replayExport.running {
+		return
+	}
+	g := rules.NewGame(history.Start)
+	for _, m := range history.Moves {
+		g.Play(m)
+	}
+	rec := rules.NewRecord(g)
+	rec.Tags["Date"] = time.Now().Format("2006.01.02")
+	opt := diagram.ReplayOptions{Size: 480, Theme: theme, Delay: time.Second}
+	file := "baag_chal-" + time.Now().Format("20060102-150405") + ".gif"
+	if abs, err := filepath.Abs(file); err == nil {
+		file = abs
+	}
+
+	replayExport.running = true
+	replayExport.file = file
+	replayExport.done = make(chan error, 1)
+	log.Printf("[Replay] Exporting %d moves to %s", g.Ply(), file)
+	go func(done chan<- error) {
+		frames, err := exportRenderer().Replay(rec, opt)
+		if err == nil {
+			var buf bytes.Buffer
+			if err = diagram.WriteReplay(&buf, "gif", frames); err == nil {
+				err = os.WriteFile(file, buf.Bytes(), 0o644)
+			}
+		}
+		done <- err
+	}(replayExport.done)
+}
+
+// updateReplayExport reports a finished export. It is called every frame.
+func updateReplayExport() {
+	if !replayExport.running {
+		return
+	}
+	select {
+	case err := <-replayExport.done:
+		replayExport.running = false
+		d := &dialog{Title: "Replay saved", Message: "The game was saved as " + replayExport.file + "."}
+		if err != nil {
+			log.Printf("[Replay] %v", err)
+			d = &dialog{Title: "Replay failed", Message: err.Error()}
+		}
+		d.Buttons = []dialogButton{{Label: "OK", Color: okColor, Default: true, Cancel: true}}
+		pushDialog(d)
+		announce(d.Title + ". " + d.Message)
+	default:
+	}
+}
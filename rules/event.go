@@ -0,0 +1,63 @@
+package rules
+
+// Event is something that happens in a game that a front-end may want to
+// react to, for example with a sound.
+type Event int
+
+const (
+	EventPlace Event = iota + 1
+	EventMove
+	EventCapture
+	// EventIllegal is a move the rules rejected.
+	EventIllegal
+	EventGoatsWin
+	EventTigersWin
+	EventDraw
+	// EventClockLow is for front-ends that keep a clock, when the side to
+	// move is running out of time; the rules have no clock of their own.
+	EventClockLow
+)
+
+var eventNames = [...]string{
+	EventPlace:     "place",
+	EventMove:      "move",
+	EventCapture:   "capture",
+	EventIllegal:   "illegal",
+	EventGoatsWin:  "goats-win",
+	EventTigersWin: "tigers-win",
+	EventDraw:      "draw",
+	EventClockLow:  "clock-low",
+}
+
+func (e Event) String() string {
+	if e > 0 && int(e) < len(eventNames) {
+		return eventNames[e]
+	}
+	return "unknown"
+}
+
+// MoveEvents returns the events of the last move played in g: the kind of
+// move and, if it ended the game, the result.
+func (g *Game) MoveEvents() []Event {
+	if g.Ply() == 0 {
+		return nil
+	}
+	var events []Event
+	switch m := g.Moves[g.Ply()-1]; {
+	case m.IsPlacement():
+		events = append(events, EventPlace)
+	case m.IsJump():
+		events = append(events, EventCapture)
+	default:
+		events = append(events, EventMove)
+	}
+	switch g.Result() {
+	case GoatsWin:
+		events = append(events, EventGoatsWin)
+	case TigersWin:
+		events = append(events, EventTigersWin)
+	case Draw:
+		events = append(events, EventDraw)
+	}
+	return events
+}
@@ -0,0 +1,155 @@
+package rules
+
+import (
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
+
+// checkInvariants fails if p is not a position the rules can reach: four
+// tigers, every goat accounted for, and only pieces on the board.
+func checkInvariants(tb testing.TB, p Position) {
+	tb.Helper()
+	tigers, goats := 0, 0
+	for x := range Size {
+		for y := range Size {
+			switch p.Board[x][y] {
+			case Tiger:
+				tigers++
+			case Goat:
+				goats++
+			case Empty:
+			default:
+				tb.Fatalf("%v: %d on %s", p, p.Board[x][y], PointString([2]int{x, y}))
+			}
+		}
+	}
+	if tigers != 4 {
+		tb.Fatalf("%v: %d tigers", p, tigers)
+	}
+	if total := goats + p.GoatsInHand() + p.CapturedGoats; total != MaxGoats {
+		tb.Fatalf("%v: %d on board + %d in hand + %d captured = %d goats",
+			p, goats, p.GoatsInHand(), p.CapturedGoats, total)
+	}
+	if p.Turn != Goat && p.Turn != Tiger {
+		tb.Fatalf("%v: turn %d", p, p.Turn)
+	}
+}
+
+// playout plays a game from the start, taking the move choose picks out of
+// n legal ones, and checks the rules along the way. It stops when the game
+// is over or choose returns -1.
+func playout(tb testing.TB, choose func(n int) int) *Game {
+	tb.Helper()
+	g := NewGame(NewPosition())
+	for g.Result() == Ongoing {
+		p := g.Position()
+		moves := p.LegalMoves()
+		if len(moves) == 0 {
+			tb.Fatalf("%v: no legal moves in an ongoing game", p)
+		}
+		i := choose(len(moves))
+		if i < 0 {
+			break
+		}
+		m := moves[i]
+		if !OnBoard(m.To) || !m.IsPlacement() && !OnBoard(m.From) {
+			tb.Fatalf("%v: move %v leaves the board", p, m)
+		}
+		if err := g.Play(m); err != nil {
+			tb.Fatalf("%v: legal move %v rejected: %v", p, m, err)
+		}
+		next := g.Position()
+		checkInvariants(tb, next)
+		if next.PlacedGoats < p.PlacedGoats || next.CapturedGoats < p.CapturedGoats {
+			tb.Fatalf("%v after %v: goat counters went down from %v", next, m, p)
+		}
+		if next.Turn != Opponent(p.Turn) {
+			tb.Fatalf("%v after %v: turn did not pass", next, m)
+		}
+	}
+	return g
+}
+
+func TestRandomPlayouts(t *testing.T) {
+	rng := rand.New(rand.NewPCG(1, 2))
+	games := 500
+	if testing.Short() {
+		games = 50
+	}
+	for range games {
+		plies := 0
+		playout(t, func(n int) int {
+			if plies++; plies > 400 {
+				return -1
+			}
+			return rng.IntN(n)
+		})
+	}
+}
+
+func FuzzPlayout(f *testing.F) {
+	f.Add([]byte{})
+	f.Add([]byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9})
+	f.Add([]byte("tigers eat goats, goats trap tigers"))
+	f.Fuzz(func(t *testing.T, choices []byte) {
+		i := 0
+		playout(t, func(n int) int {
+			if i == len(choices) {
+				return -1
+			}
+			i++
+			return int(choices[i-1]) % n
+		})
+	})
+}
+
+func FuzzParsePosition(f *testing.F) {
+	f.Add("T3T/5/5/5/T3T g 0 0")
+	f.Add("T3T/5/2G2/5/T3T t 1 0")
+	f.Add("TGGGT/GGGGG/GG1GG/GGGG1/T1G1T g 20 3")
+	f.Add("T3T/5/5/5/T3T")
+	f.Add("t3t/5/5/5/t3t T")
+	f.Fuzz(func(t *testing.T, s string) {
+		p, err := ParsePosition(s)
+		if err != nil {
+			return
+		}
+		checkInvariants(t, p)
+		str := p.String()
+		q, err := ParsePosition(str)
+		if err != nil {
+			t.Fatalf("%q parses as %q, which does not: %v", s, str, err)
+		}
+		if q != p {
+			t.Fatalf("%q parses as %q, which parses as %q", s, str, q.String())
+		}
+	})
+}
+
+func FuzzParseRecord(f *testing.F) {
+	f.Add("[Event \"Club night\"]\n[Result \"*\"]\n\n1. c3 a1-b1 2. c1 {a comment} b1xd1 *\n")
+	f.Add("1.c3 a1-b1 2.c1 b1xd1 *")
+	f.Add("[Position \"T3T/5/2G2/5/T3T t 1 0\"]\n\n1... a1-b1 {tiger} *\n")
+	f.Add("{before} 1. c3 {after} {again} *")
+	f.Fuzz(func(t *testing.T, s string) {
+		rec, err := ParseRecord(s)
+		if err != nil {
+			return
+		}
+		for i := range rec.Game.Ply() + 1 {
+			checkInvariants(t, rec.Game.PositionAt(i))
+		}
+		out := rec.String()
+		again, err := ParseRecord(out)
+		if err != nil {
+			t.Fatalf("record written as\n%s\ndoes not parse: %v", out, err)
+		}
+		if again.Game.Start != rec.Game.Start || !slices.Equal(again.Game.Moves, rec.Game.Moves) {
+			t.Fatalf("record written as\n%s\nparses as a different game", out)
+		}
+		if again.String() != out {
+			t.Fatalf("record written as\n%s\nis written again as\n%s", out, again.String())
+		}
+	})
+}
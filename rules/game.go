@@ -0,0 +1,91 @@
+package rules
+
+// Game is a position together with the moves that led to it.
+type Game struct {
+	Start Position
+	Moves []Move
+
+	// positions[i] is the position before Moves[i]; the last entry is the
+	// current position.
+	positions []Position
+}
+
+// NewGame starts a game from start.
+func NewGame(start Position) *Game {
+	return &Game{Start: start, positions: []Position{start}}
+}
+
+// Position returns the current position.
+func (g *Game) Position() Position {
+	return g.positions[len(g.positions)-1]
+}
+
+// PositionAt returns the position after the first ply moves.
+func (g *Game) PositionAt(ply int) Position {
+	return g.positions[ply]
+}
+
+// Ply returns the number of moves played.
+func (g *Game) Ply() int {
+	return len(g.Moves)
+}
+
+// MoveNumber is the number of the goat/tiger pair containing move i,
+// counting from 1. A game started with the tigers to move begins with a
+// pair whose goat move is missing.
+func (g *Game) MoveNumber(i int) int {
+	if g.Start.Turn == Tiger {
+		return (i+1)/2 + 1
+	}
+	return i/2 + 1
+}
+
+// Play checks and plays m.
+func (g *Game) Play(m Move) error {
+	if g.Result() != Ongoing {
+		return ErrGameOver
+	}
+	p := g.Position()
+	if err := p.Play(m); err != nil {
+		return err
+	}
+	g.Moves = append(g.Moves, m)
+	g.positions = append(g.positions, p)
+	return nil
+}
+
+// Undo takes back the last move, reporting false if there is none.
+func (g *Game) Undo() bool {
+	if len(g.Moves) == 0 {
+		return false
+	}
+	g.Moves = g.Moves[:len(g.Moves)-1]
+	g.positions = g.positions[:len(g.positions)-1]
+	return true
+}
+
+// Repetitions counts how often the current position has occurred so far,
+// including now.
+func (g *Game) Repetitions() int {
+	cur := g.Position()
+	n := 0
+	for _, p := range g.positions {
+		if p == cur {
+			n++
+		}
+	}
+	return n
+}
+
+// Result is the position's result, except that the third occurrence of the
+// same position is a draw.
+func (g *Game) Result() Result {
+	p := g.Position()
+	if r := p.Result(); r != Ongoing {
+		return r
+	}
+	if g.Repetitions() >= 3 {
+		return Draw
+	}
+	return Ongoing
+}
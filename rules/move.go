@@ -0,0 +1,99 @@
+package rules
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Move is a goat placement (From is NoPoint), a step along a line or a
+// tiger jump over a goat.
+type Move struct {
+	From, To [2]int
+}
+
+// Place returns the placement of a goat on to.
+func Place(to [2]int) Move {
+	return Move{From: NoPoint, To: to}
+}
+
+// IsPlacement reports whether m puts a new goat on the board.
+func (m Move) IsPlacement() bool {
+	return m.From == NoPoint
+}
+
+// IsJump reports whether m is two points long, i.e. a capture.
+func (m Move) IsJump() bool {
+	if m.IsPlacement() {
+		return false
+	}
+	dx := abs(m.To[0] - m.From[0])
+	dy := abs(m.To[1] - m.From[1])
+	return (dx == 2 || dx == 0) && (dy == 2 || dy == 0) && dx+dy > 0
+}
+
+// Captured returns the point jumped over by a capture.
+func (m Move) Captured() [2]int {
+	return [2]int{(m.From[0] + m.To[0]) / 2, (m.From[1] + m.To[1]) / 2}
+}
+
+// String formats m in move notation: "c3" for a placement, "c3-c4" for a
+// step and "a1xc3" for a capture.
+func (m Move) String() string {
+	switch {
+	case m.IsPlacement():
+		return PointString(m.To)
+	case m.IsJump():
+		return PointString(m.From) + "x" + PointString(m.To)
+	default:
+		return PointString(m.From) + "-" + PointString(m.To)
+	}
+}
+
+// PointString names a point with a file letter a-e (x) and a rank 1-5 (y).
+func PointString(pt [2]int) string {
+	if !OnBoard(pt) {
+		return "??"
+	}
+	return string(rune('a'+pt[0])) + string(rune('1'+pt[1]))
+}
+
+// ParsePoint parses a point name such as "c3".
+func ParsePoint(s string) ([2]int, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if len(s) != 2 {
+		return NoPoint, fmt.Errorf("bad point %q", s)
+	}
+	pt := [2]int{int(s[0] - 'a'), int(s[1] - '1')}
+	if !OnBoard(pt) {
+		return NoPoint, fmt.Errorf("bad point %q", s)
+	}
+	return pt, nil
+}
+
+// ParseMove parses move notation. The separator between the two points is
+// optional, so "c3c4", "c3-c4" and "c3xc4" are all accepted.
+func ParseMove(s string) (Move, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if len(s) == 2 {
+		to, err := ParsePoint(s)
+		if err != nil {
+			return Move{}, err
+		}
+		return Place(to), nil
+	}
+	if len(s) == 5 && (s[2] == '-' || s[2] == 'x') {
+		s = s[:2] + s[3:]
+	}
+	if len(s) != 4 {
+		return Move{}, fmt.Errorf("bad move %q", s)
+	}
+	from, err := ParsePoint(s[:2])
+	if err != nil {
+		return Move{}, err
+	}
+	to, err := ParsePoint(s[2:])
+	if err != nil {
+		return Move{}, err
+	}
+	return Move{From: from, To: to}, nil
+}
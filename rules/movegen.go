@@ -0,0 +1,149 @@
+package rules
+
+// Result is the outcome of a game.
+type Result int
+
+const (
+	Ongoing Result = iota
+	GoatsWin
+	TigersWin
+	Draw
+)
+
+func (r Result) String() string {
+	switch r {
+	case GoatsWin:
+		return "goats win"
+	case TigersWin:
+		return "tigers win"
+	case Draw:
+		return "draw"
+	}
+	return "ongoing"
+}
+
+// LegalMoves returns every legal move for the side to move. Captures are
+// listed before quiet tiger moves.
+func (p *Position) LegalMoves() []Move {
+	if p.CapturedGoats >= GoatsToWin {
+		return nil
+	}
+	var moves []Move
+
+	if p.Turn == Goat {
+		for x := 0; x < Size; x++ {
+			for y := 0; y < Size; y++ {
+				pt := [2]int{x, y}
+				if p.PlacedGoats < MaxGoats {
+					if p.At(pt) == Empty {
+						moves = append(moves, Place(pt))
+					}
+					continue
+				}
+				if p.At(pt) != Goat {
+					continue
+				}
+				for _, to := range Connections[pt] {
+					if p.At(to) == Empty && Connected(pt, to) {
+						moves = append(moves, Move{From: pt, To: to})
+					}
+				}
+			}
+		}
+		return moves
+	}
+
+	var quiet []Move
+	for x := 0; x < Size; x++ {
+		for y := 0; y < Size; y++ {
+			pt := [2]int{x, y}
+			if p.At(pt) != Tiger {
+				continue
+			}
+			jumps, steps := p.tigerMoves(pt)
+			moves = append(moves, jumps...)
+			quiet = append(quiet, steps...)
+		}
+	}
+	return append(moves, quiet...)
+}
+
+// tigerMoves returns the captures and the quiet moves of the tiger at pt,
+// whoever is to move.
+func (p *Position) tigerMoves(pt [2]int) (jumps, steps []Move) {
+	for _, mid := range Connections[pt] {
+		if !Connected(pt, mid) {
+			continue
+		}
+		switch p.At(mid) {
+		case Empty:
+			steps = append(steps, Move{From: pt, To: mid})
+		case Goat:
+			to := [2]int{2*mid[0] - pt[0], 2*mid[1] - pt[1]}
+			if OnBoard(to) && p.At(to) == Empty && Connected(mid, to) {
+				jumps = append(jumps, Move{From: pt, To: to})
+			}
+		}
+	}
+	return jumps, steps
+}
+
+// ThreatenedGoats returns the goats a tiger could capture on its next
+// move, whoever is to move now.
+func (p *Position) ThreatenedGoats() [][2]int {
+	var goats [][2]int
+	seen := map[[2]int]bool{}
+	for x := 0; x < Size; x++ {
+		for y := 0; y < Size; y++ {
+			pt := [2]int{x, y}
+			if p.At(pt) != Tiger {
+				continue
+			}
+			jumps, _ := p.tigerMoves(pt)
+			for _, m := range jumps {
+				if c := m.Captured(); !seen[c] {
+					seen[c] = true
+					goats = append(goats, c)
+				}
+			}
+		}
+	}
+	return goats
+}
+
+// TrappedTigers returns the tigers that can neither move nor capture.
+func (p *Position) TrappedTigers() [][2]int {
+	var tigers [][2]int
+	for x := 0; x < Size; x++ {
+		for y := 0; y < Size; y++ {
+			pt := [2]int{x, y}
+			if p.At(pt) != Tiger {
+				continue
+			}
+			if jumps, steps := p.tigerMoves(pt); len(jumps) == 0 && len(steps) == 0 {
+				tigers = append(tigers, pt)
+			}
+		}
+	}
+	return tigers
+}
+
+// HasLegalMove reports whether the side to move can move at all.
+func (p *Position) HasLegalMove() bool {
+	return len(p.LegalMoves()) > 0
+}
+
+// Result reports whether the position is decided: tigers win after
+// GoatsToWin captures, and a side with no legal move loses.
+func (p *Position) Result() Result {
+	if p.CapturedGoats >= GoatsToWin {
+		return TigersWin
+	}
+	if !p.HasLegalMove() {
+		if p.Turn == Tiger {
+			return GoatsWin
+		}
+		return TigersWin
+	}
+	return Ongoing
+}
@@ -0,0 +1,44 @@
+package rules
+
+// Perft counts the move sequences of depth plies from p, following every
+// legal move. It is a check of the move generator against known counts.
+// Positions where the game is over by capture or having no move end a
+// sequence early and are not counted.
+func Perft(p Position, depth int) uint64 {
+	if depth == 0 {
+		return 1
+	}
+	moves := p.LegalMoves()
+	if depth == 1 {
+		return uint64(len(moves))
+	}
+	var n uint64
+	for _, m := range moves {
+		next := p
+		next.apply(m)
+		n += Perft(next, depth-1)
+	}
+	return n
+}
+
+// MoveCount is a move with the perft count below it.
+type MoveCount struct {
+	Move  Move
+	Nodes uint64
+}
+
+// Divide splits Perft(p, depth) by the first move, in LegalMoves order.
+// Comparing it with another move generator's shows which move leads to a
+// discrepancy, to be divided again one ply deeper.
+func Divide(p Position, depth int) []MoveCount {
+	if depth < 1 {
+		return nil
+	}
+	var counts []MoveCount
+	for _, m := range p.LegalMoves() {
+		next := p
+		next.apply(m)
+		counts = append(counts, MoveCount{m, Perft(next, depth-1)})
+	}
+	return counts
+}
@@ -0,0 +1,124 @@
+package rules
+
+import "testing"
+
+// perftTests are counts checked against an independent move generator.
+var perftTests = []struct {
+	name   string
+	pos    string
+	counts []uint64
+}{
+	{
+		name:   "start",
+		pos:    "T3T/5/5/5/T3T g 0 0",
+		counts: []uint64{21, 252, 5052, 68204, 1304788},
+	},
+	{
+		name:   "placement with captures",
+		pos:    "T1G1T/1G3/2G2/G4/T3T t 4 0",
+		counts: []uint64{11, 188, 2243, 36376, 440987},
+	},
+	{
+		name:   "goats moving",
+		pos:    "TGGGT/GGGGG/GG1GG/GGGG1/T1G1T g 20 3",
+		counts: []uint64{14, 68, 841, 3966, 43042},
+	},
+	{
+		name:   "one capture from the end",
+		pos:    "TGG1T/GGGGG/GGTG1/GGGGG/T1G2 t 20 4",
+		counts: []uint64{5, 26, 148, 874, 4980},
+	},
+}
+
+func TestPerft(t *testing.T) {
+	for _, tt := range perftTests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := ParsePosition(tt.pos)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i, want := range tt.counts {
+				if got := Perft(p, i+1); got != want {
+					t.Errorf("perft %d = %d, want %d", i+1, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestPerftStartDepth6(t *testing.T) {
+	if testing.Short() {
+		t.Skip("slow")
+	}
+	if got, want := Perft(NewPosition(), 6), uint64(18592000); got != want {
+		t.Errorf("perft 6 = %d, want %d", got, want)
+	}
+}
+
+func TestDivide(t *testing.T) {
+	for _, tt := range perftTests {
+		p, err := ParsePosition(tt.pos)
+		if err != nil {
+			t.Fatal(err)
+		}
+		depth := len(tt.counts) - 1
+		var total uint64
+		for _, c := range Divide(p, depth) {
+			next := p
+			if err := next.Play(c.Move); err != nil {
+				t.Fatalf("%s: divide move %v: %v", tt.name, c.Move, err)
+			}
+			if want := Perft(next, depth-1); c.Nodes != want {
+				t.Errorf("%s: %v has %d nodes, perft %d", tt.name, c.Move, c.Nodes, want)
+			}
+			total += c.Nodes
+		}
+		if want := tt.counts[depth-1]; total != want {
+			t.Errorf("%s: divide %d totals %d, want %d", tt.name, depth, total, want)
+		}
+	}
+}
+
+// TestConnections checks the adjacency table against the board: every
+// point joins its orthogonal neighbours, points whose coordinates add up to
+// an even number also join their diagonal ones, and each line goes both
+// ways.
+func TestConnections(t *testing.T) {
+	if len(Connections) != Size*Size {
+		t.Errorf("%d points in Connections, want %d", len(Connections), Size*Size)
+	}
+	for x := 0; x < Size; x++ {
+		for y := 0; y < Size; y++ {
+			pt := [2]int{x, y}
+			want := map[[2]int]bool{}
+			for dx := -1; dx <= 1; dx++ {
+				for dy := -1; dy <= 1; dy++ {
+					q := [2]int{x + dx, y + dy}
+					diagonal := dx != 0 && dy != 0
+					if q == pt || !OnBoard(q) || diagonal && (x+y)%2 != 0 {
+						continue
+					}
+					want[q] = true
+				}
+			}
+			got := map[[2]int]bool{}
+			for _, q := range Connections[pt] {
+				if got[q] {
+					t.Errorf("%s lists %s twice", PointString(pt), PointString(q))
+				}
+				got[q] = true
+				if !want[q] {
+					t.Errorf("%s lists %s, which is not joined to it", PointString(pt), PointString(q))
+				}
+			}
+			for q := range want {
+				if !got[q] {
+					t.Errorf("%s does not list %s", PointString(pt), PointString(q))
+				}
+				if !Connected(q, pt) {
+					t.Errorf("%s is not connected back to %s", PointString(q), PointString(pt))
+				}
+			}
+		}
+	}
+}
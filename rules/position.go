@@ -0,0 +1,235 @@
+// Package rules implements the Baag-Chal rules independently of any
+// front-end, so the OpenGL client, engines and tools all agree on what a
+// legal move is.
+package rules
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Piece values, matching the encoding of the board in the GUI.
+const (
+	Empty = 0
+	Goat  = 1
+	Tiger = 2
+)
+
+const (
+	// Size is the number of points along each side of the board.
+	Size = 5
+	// MaxGoats is the number of goats the goat player starts with in hand.
+	MaxGoats = 20
+	// GoatsToWin is the number of captures that wins the game for the tigers.
+	GoatsToWin = 5
+)
+
+// NoPoint marks the missing origin of a goat placement.
+var NoPoint = [2]int{-1, -1}
+
+// Connections lists, for every point, the points joined to it by a line.
+var Connections = map[[2]int][][2]int{
+	{0, 0}: {{0, 1}, {1, 1}, {1, 0}},
+	{0, 1}: {{0, 0}, {0, 2}, {1, 1}},
+	{0, 2}: {{0, 1}, {0, 3}, {1, 1}, {1, 2}, {1, 3}},
+	{0, 3}: {{0, 2}, {0, 4}, {1, 3}},
+	{0, 4}: {{0, 3}, {1, 3}, {1, 4}},
+
+	{1, 0}: {{0, 0}, {1, 1}, {2, 0}},
+	{1, 1}: {{0, 0}, {0, 1}, {0, 2}, {1, 0}, {1, 2}, {2, 0}, {2, 2}, {2, 1}},
+	{1, 2}: {{0, 2}, {1, 1}, {1, 3}, {2, 2}},
+	{1, 3}: {{0, 2}, {0, 3}, {0, 4}, {1, 2}, {1, 4}, {2, 4}, {2, 2}, {2, 3}},
+	{1, 4}: {{0, 4}, {1, 3}, {2, 4}},
+
+	{2, 0}: {{1, 0}, {2, 1}, {3, 0}, {3, 1}, {1, 1}},
+	{2, 1}: {{1, 1}, {2, 0}, {2, 2}, {3, 1}},
+	{2, 2}: {{1, 1}, {1, 2}, {1, 3}, {2, 1}, {2, 3}, {3, 1}, {3, 3}, {3, 2}},
+	{2, 3}: {{1, 3}, {2, 2}, {2, 4}, {3, 3}},
+	{2, 4}: {{1, 3}, {1, 4}, {2, 3}, {3, 3}, {3, 4}},
+
+	{3, 0}: {{2, 0}, {3, 1}, {4, 0}},
+	{3, 1}: {{2, 0}, {2, 1}, {2, 2}, {3, 0}, {3, 2}, {4, 0}, {4, 2}, {4, 1}},
+	{3, 2}: {{2, 2}, {3, 1}, {3, 3}, {4, 2}},
+	{3, 3}: {{2, 2}, {2, 3}, {2, 4}, {3, 2}, {3, 4}, {4, 4}, {4, 2}, {4, 3}},
+	{3, 4}: {{2, 4}, {3, 3}, {4, 4}},
+
+	{4, 0}: {{3, 0}, {3, 1}, {4, 1}},
+	{4, 1}: {{3, 1}, {4, 0}, {4, 2}},
+	{4, 2}: {{3, 1}, {3, 2}, {3, 3}, {4, 1}, {4, 3}},
+	{4, 3}: {{3, 3}, {4, 2}, {4, 4}},
+	{4, 4}: {{3, 3}, {3, 4}, {4, 3}},
+}
+
+// Reasons a move can be rejected by CheckMove.
+var (
+	ErrGameOver      = errors.New("the game is over")
+	ErrOffBoard      = errors.New("point is off the board")
+	ErrOccupied      = errors.New("destination is not empty")
+	ErrNotYourPiece  = errors.New("origin does not hold a piece of the side to move")
+	ErrNotConnected  = errors.New("points are not joined by a line")
+	ErrNoGoatToJump  = errors.New("jump does not pass over a goat")
+	ErrGoatsInHand   = errors.New("goats must all be placed before one can move")
+	ErrNoGoatsInHand = errors.New("all goats have already been placed")
+	ErrTigerPlace    = errors.New("tigers cannot be placed")
+)
+
+// Position is a complete game state: the board, the side to move and the
+// goat counters.
+type Position struct {
+	// Board holds Empty, Goat or Tiger, indexed [x][y] with y=0 at the bottom.
+	Board [Size][Size]int
+	// Turn is Goat or Tiger.
+	Turn int
+
+	PlacedGoats   int
+	CapturedGoats int
+}
+
+// NewPosition returns the starting position: tigers in the corners and all
+// goats in hand, goats to move.
+func NewPosition() Position {
+	var p Position
+	p.Board[0][0] = Tiger
+	p.Board[0][4] = Tiger
+	p.Board[4][0] = Tiger
+	p.Board[4][4] = Tiger
+	p.Turn = Goat
+	return p
+}
+
+// OnBoard reports whether pt is one of the 25 board points.
+func OnBoard(pt [2]int) bool {
+	return pt[0] >= 0 && pt[1] >= 0 && pt[0] < Size && pt[1] < Size
+}
+
+// Connected reports whether a single step leads from a to b.
+func Connected(a, b [2]int) bool {
+	// Only neighbouring points count as a step.
+	if abs(b[0]-a[0]) > 1 || abs(b[1]-a[1]) > 1 {
+		return false
+	}
+	for _, conn := range Connections[a] {
+		if conn == b {
+			return true
+		}
+	}
+	return false
+}
+
+// At returns the piece on pt.
+func (p *Position) At(pt [2]int) int {
+	return p.Board[pt[0]][pt[1]]
+}
+
+// GoatsInHand is the number of goats still waiting to be placed.
+func (p *Position) GoatsInHand() int {
+	return MaxGoats - p.PlacedGoats
+}
+
+// GoatsOnBoard is the number of goats currently on the board.
+func (p *Position) GoatsOnBoard() int {
+	return p.PlacedGoats - p.CapturedGoats
+}
+
+// Summary is the one-line goat count shown by the front-ends.
+func (p *Position) Summary() string {
+	return fmt.Sprintf("Goats Placed: %d | Captured: %d | Remaining: %d",
+		p.PlacedGoats, p.CapturedGoats, p.GoatsInHand())
+}
+
+// CheckMove returns nil if m is legal for the side to move, or the reason
+// it is not.
+func (p *Position) CheckMove(m Move) error {
+	if p.CapturedGoats >= GoatsToWin {
+		return ErrGameOver
+	}
+	if !OnBoard(m.To) {
+		return ErrOffBoard
+	}
+	if p.At(m.To) != Empty {
+		return ErrOccupied
+	}
+
+	if m.IsPlacement() {
+		if p.Turn != Goat {
+			return ErrTigerPlace
+		}
+		if p.PlacedGoats >= MaxGoats {
+			return ErrNoGoatsInHand
+		}
+		return nil
+	}
+
+	if !OnBoard(m.From) {
+		return ErrOffBoard
+	}
+	if p.At(m.From) != p.Turn {
+		return ErrNotYourPiece
+	}
+	if p.Turn == Goat && p.PlacedGoats < MaxGoats {
+		return ErrGoatsInHand
+	}
+
+	// Single step along a line.
+	if Connected(m.From, m.To) {
+		return nil
+	}
+
+	// Tigers may also jump over a goat along a line.
+	if p.Turn == Tiger && m.IsJump() {
+		mid := m.Captured()
+		if !Connected(m.From, mid) || !Connected(mid, m.To) {
+			return ErrNotConnected
+		}
+		if p.At(mid) != Goat {
+			return ErrNoGoatToJump
+		}
+		return nil
+	}
+	return ErrNotConnected
+}
+
+// IsValidMove reports whether moving from one point to another is legal.
+func (p *Position) IsValidMove(from, to [2]int) bool {
+	return p.CheckMove(Move{From: from, To: to}) == nil
+}
+
+// Play checks m and applies it, switching the side to move.
+func (p *Position) Play(m Move) error {
+	if err := p.CheckMove(m); err != nil {
+		return err
+	}
+	p.apply(m)
+	return nil
+}
+
+// apply makes a move that is already known to be legal.
+func (p *Position) apply(m Move) {
+	if m.IsPlacement() {
+		p.Board[m.To[0]][m.To[1]] = Goat
+		p.PlacedGoats++
+	} else {
+		piece := p.At(m.From)
+		p.Board[m.From[0]][m.From[1]] = Empty
+		p.Board[m.To[0]][m.To[1]] = piece
+		if m.IsJump() {
+			mid := m.Captured()
+			p.Board[mid[0]][mid[1]] = Empty
+			p.CapturedGoats++
+		}
+	}
+	p.Turn = Opponent(p.Turn)
+}
+
+// Opponent returns the other side.
+func Opponent(side int) int {
+	return 3 - side
+}
+
+// Utility for integer absolute value
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,129 @@
+package rules
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// String encodes p as a position string: the ranks from 5 down to 1 separated
+// by '/', using 'G', 'T' and digits for runs of empty points, followed by the
+// side to move ('g' or 't'), the goats placed and the goats captured.
+// The starting position is "T3T/5/5/5/T3T g 0 0".
+func (p Position) String() string {
+	var b strings.Builder
+	for y := Size - 1; y >= 0; y-- {
+		empty := 0
+		for x := 0; x < Size; x++ {
+			c := byte(0)
+			switch p.Board[x][y] {
+			case Goat:
+				c = 'G'
+			case Tiger:
+				c = 'T'
+			}
+			if c == 0 {
+				empty++
+				continue
+			}
+			if empty > 0 {
+				b.WriteByte(byte('0' + empty))
+				empty = 0
+			}
+			b.WriteByte(c)
+		}
+		if empty > 0 {
+			b.WriteByte(byte('0' + empty))
+		}
+		if y > 0 {
+			b.WriteByte('/')
+		}
+	}
+	side := "g"
+	if p.Turn == Tiger {
+		side = "t"
+	}
+	fmt.Fprintf(&b, " %s %d %d", side, p.PlacedGoats, p.CapturedGoats)
+	return b.String()
+}
+
+// ParsePosition decodes a position string produced by Position.String. The
+// side and counters may be omitted, in which case goats are to move and the
+// counters are derived from the goats on the board.
+func ParsePosition(s string) (Position, error) {
+	var p Position
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		return p, fmt.Errorf("empty position string")
+	}
+	ranks := strings.Split(fields[0], "/")
+	if len(ranks) != Size {
+		return p, fmt.Errorf("position %q: want %d ranks, got %d", s, Size, len(ranks))
+	}
+	goats, tigers := 0, 0
+	for i, rank := range ranks {
+		y := Size - 1 - i
+		x := 0
+		for _, c := range rank {
+			switch {
+			case c >= '1' && c <= '5':
+				x += int(c - '0')
+				continue
+			case x >= Size:
+				return p, fmt.Errorf("position %q: rank %d is too long", s, y+1)
+			case c == 'G' || c == 'g':
+				p.Board[x][y] = Goat
+				goats++
+			case c == 'T' || c == 't':
+				p.Board[x][y] = Tiger
+				tigers++
+			default:
+				return p, fmt.Errorf("position %q: unexpected %q", s, c)
+			}
+			x++
+		}
+		if x != Size {
+			return p, fmt.Errorf("position %q: rank %d has %d points", s, y+1, x)
+		}
+	}
+	if tigers != 4 {
+		return p, fmt.Errorf("position %q: want 4 tigers, got %d", s, tigers)
+	}
+
+	p.Turn = Goat
+	p.PlacedGoats = goats
+	if len(fields) > 1 {
+		switch fields[1] {
+		case "g", "G":
+			p.Turn = Goat
+		case "t", "T":
+			p.Turn = Tiger
+		default:
+			return p, fmt.Errorf("position %q: bad side %q", s, fields[1])
+		}
+	}
+	if len(fields) > 2 {
+		n, err := strconv.Atoi(fields[2])
+		if err != nil {
+			return p, fmt.Errorf("position %q: bad placed count: %v", s, err)
+		}
+		p.PlacedGoats = n
+	}
+	if len(fields) > 3 {
+		n, err := strconv.Atoi(fields[3])
+		if err != nil {
+			return p, fmt.Errorf("position %q: bad captured count: %v", s, err)
+		}
+		p.CapturedGoats = n
+	}
+	if len(fields) > 4 {
+		return p, fmt.Errorf("position %q: trailing fields", s)
+	}
+
+	if p.PlacedGoats < 0 || p.PlacedGoats > MaxGoats || p.CapturedGoats < 0 ||
+		p.PlacedGoats-p.CapturedGoats != goats {
+		return p, fmt.Errorf("position %q: %d goats on board do not match %d placed and %d captured",
+			s, goats, p.PlacedGoats, p.CapturedGoats)
+	}
+	return p, nil
+}
@@ -0,0 +1,328 @@
+package rules
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// A Record is a game with its tags, stored in a PGN-like text format:
+//
+//	[Event "Club night"]
+//	[Goat "alice"]
+//	[Tiger "bob"]
+//	[Result "tigers"]
+//
+//	1. c3 a1-b1 2. c1 {a comment} b1xd1 ...
+//
+// Moves are numbered in goat/tiger pairs. A game not starting from the
+// usual position carries a Position tag holding the position string. The
+// Result tag is "goats", "tigers", "draw" or "*" for an unfinished game.
+type Record struct {
+	Tags map[string]string
+	Game *Game
+	// Comments maps a ply to the comment following that move; ply 0 is a
+	// comment before the first move.
+	Comments map[int]string
+}
+
+// tagOrder lists the tags written first, in this order.
+var tagOrder = []string{"Event", "Site", "Date", "Round", "Goat", "Tiger", "Result", "Position"}
+
+// NewRecord wraps g in a record with no tags.
+func NewRecord(g *Game) *Record {
+	return &Record{Tags: map[string]string{}, Game: g, Comments: map[int]string{}}
+}
+
+// Tag is the value of the Result tag for r.
+func (r Result) Tag() string {
+	switch r {
+	case GoatsWin:
+		return "goats"
+	case TigersWin:
+		return "tigers"
+	case Draw:
+		return "draw"
+	}
+	return "*"
+}
+
+// ParseResult parses a Result tag value.
+func ParseResult(s string) (Result, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "goats", "goat":
+		return GoatsWin, nil
+	case "tigers", "tiger":
+		return TigersWin, nil
+	case "draw":
+		return Draw, nil
+	case "*", "":
+		return Ongoing, nil
+	}
+	return Ongoing, fmt.Errorf("bad result %q", s)
+}
+
+// Result returns the Result tag if set, otherwise the result on the board.
+// The tag wins so that resignations and time forfeits are kept.
+func (rec *Record) Result() Result {
+	if tag, ok := rec.Tags["Result"]; ok {
+		if r, err := ParseResult(tag); err == nil && r != Ongoing {
+			return r
+		}
+	}
+	return rec.Game.Result()
+}
+
+// String formats the record.
+func (rec *Record) String() string {
+	var b strings.Builder
+	rec.WriteTo(&b)
+	return b.String()
+}
+
+// WriteTo writes the record followed by a blank line, so that records can
+// be concatenated into one file.
+func (rec *Record) WriteTo(w io.Writer) (int64, error) {
+	var b strings.Builder
+	tags := map[string]string{}
+	for k, v := range rec.Tags {
+		tags[k] = v
+	}
+	tags["Result"] = rec.Result().Tag()
+	if rec.Game.Start != NewPosition() {
+		tags["Position"] = rec.Game.Start.String()
+	} else {
+		delete(tags, "Position")
+	}
+
+	var names []string
+	for _, k := range tagOrder {
+		if _, ok := tags[k]; ok {
+			names = append(names, k)
+		}
+	}
+	var rest []string
+	for k := range tags {
+		if !contains(tagOrder, k) {
+			rest = append(rest, k)
+		}
+	}
+	sort.Strings(rest)
+	for _, k := range append(names, rest...) {
+		fmt.Fprintf(&b, "[%s %s]\n", k, strconv.Quote(tags[k]))
+	}
+	b.WriteString("\n")
+
+	var tokens []string
+	if c := rec.Comments[0]; c != "" {
+		tokens = append(tokens, "{"+c+"}")
+	}
+	for i, m := range rec.Game.Moves {
+		pos := rec.Game.PositionAt(i)
+		number := rec.Game.MoveNumber(i)
+		switch {
+		case pos.Turn == Goat:
+			tokens = append(tokens, fmt.Sprintf("%d.", number))
+		case i == 0 || rec.Comments[i] != "":
+			tokens = append(tokens, fmt.Sprintf("%d...", number))
+		}
+		tokens = append(tokens, m.String())
+		if c := rec.Comments[i+1]; c != "" {
+			tokens = append(tokens, "{"+c+"}")
+		}
+	}
+	tokens = append(tokens, tags["Result"])
+	writeWrapped(&b, tokens, 80)
+	b.WriteString("\n")
+
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+func writeWrapped(b *strings.Builder, tokens []string, width int) {
+	line := 0
+	for _, t := range tokens {
+		if line > 0 && line+1+len(t) > width {
+			b.WriteString("\n")
+			line = 0
+		}
+		if line > 0 {
+			b.WriteString(" ")
+			line++
+		}
+		b.WriteString(t)
+		line += len(t)
+	}
+	b.WriteString("\n")
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseRecord parses a single record.
+func ParseRecord(s string) (*Record, error) {
+	recs, err := ReadRecords(strings.NewReader(s))
+	if err != nil {
+		return nil, err
+	}
+	if len(recs) != 1 {
+		return nil, fmt.Errorf("want one game record, got %d", len(recs))
+	}
+	return recs[0], nil
+}
+
+// ReadRecords reads all records from r.
+func ReadRecords(r io.Reader) ([]*Record, error) {
+	var recs []*Record
+	var tags map[string]string
+	var movetext strings.Builder
+	line := 0
+
+	flush := func() error {
+		if tags == nil && strings.TrimSpace(movetext.String()) == "" {
+			return nil
+		}
+		rec, err := parseRecord(tags, movetext.String())
+		if err != nil {
+			return fmt.Errorf("game %d (ending line %d): %v", len(recs)+1, line, err)
+		}
+		recs = append(recs, rec)
+		tags = nil
+		movetext.Reset()
+		return nil
+	}
+
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		line++
+		text := strings.TrimSpace(sc.Text())
+		if strings.HasPrefix(text, "[") {
+			// A tag after movetext starts the next game.
+			if strings.TrimSpace(movetext.String()) != "" {
+				if err := flush(); err != nil {
+					return nil, err
+				}
+			}
+			k, v, err := parseTag(text)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %v", line, err)
+			}
+			if tags == nil {
+				tags = map[string]string{}
+			}
+			tags[k] = v
+			continue
+		}
+		movetext.WriteString(text)
+		movetext.WriteString("\n")
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+	return recs, nil
+}
+
+func parseTag(text string) (string, string, error) {
+	inner, ok := strings.CutSuffix(text, "]")
+	if !ok {
+		return "", "", fmt.Errorf("unterminated tag %q", text)
+	}
+	name, value, ok := strings.Cut(inner[1:], " ")
+	if !ok || name == "" {
+		return "", "", fmt.Errorf("bad tag %q", text)
+	}
+	v, err := strconv.Unquote(strings.TrimSpace(value))
+	if err != nil {
+		return "", "", fmt.Errorf("bad tag value in %q", text)
+	}
+	return name, v, nil
+}
+
+func parseRecord(tags map[string]string, movetext string) (*Record, error) {
+	start := NewPosition()
+	if s, ok := tags["Position"]; ok {
+		p, err := ParsePosition(s)
+		if err != nil {
+			return nil, err
+		}
+		start = p
+	}
+	rec := NewRecord(NewGame(start))
+	for k, v := range tags {
+		rec.Tags[k] = v
+	}
+
+	rest := movetext
+	for {
+		rest = strings.TrimSpace(rest)
+		if rest == "" {
+			break
+		}
+		if rest[0] == '{' {
+			end := strings.IndexByte(rest, '}')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated comment")
+			}
+			comment := strings.Join(strings.Fields(rest[1:end]), " ")
+			rest = rest[end+1:]
+			if comment == "" {
+				continue
+			}
+			ply := rec.Game.Ply()
+			if prev := rec.Comments[ply]; prev != "" {
+				comment = prev + " " + comment
+			}
+			rec.Comments[ply] = comment
+			continue
+		}
+		end := strings.IndexAny(rest, " \t\n{")
+		if end < 0 {
+			end = len(rest)
+		}
+		token := rest[:end]
+		rest = rest[end:]
+
+		// Move numbers may be glued to the move, as in "1.c3".
+		if i := strings.LastIndexByte(token, '.'); i >= 0 {
+			if _, err := strconv.Atoi(strings.TrimRight(token[:i+1], ".")); err != nil {
+				return nil, fmt.Errorf("bad move number %q", token)
+			}
+			token = token[i+1:]
+			if token == "" {
+				continue
+			}
+		}
+		if _, err := ParseResult(token); err == nil && !isPointLike(token) {
+			if _, ok := rec.Tags["Result"]; !ok {
+				rec.Tags["Result"] = token
+			}
+			continue
+		}
+		m, err := ParseMove(token)
+		if err != nil {
+			return nil, err
+		}
+		if err := rec.Game.Play(m); err != nil {
+			return nil, fmt.Errorf("move %d %s: %v", rec.Game.Ply()+1, token, err)
+		}
+	}
+	return rec, nil
+}
+
+// isPointLike reports whether a token could be move notation rather than a
+// result.
+func isPointLike(token string) bool {
+	return len(token) >= 2 && token[0] >= 'a' && token[0] <= 'e' && token[1] >= '1' && token[1] <= '5'
+}
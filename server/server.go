@@ -0,0 +1,346 @@
+// Package server exposes game management over a REST/JSON HTTP API backed
+// by the rules package. The API is described in openapi.yaml, served at
+// /openapi.yaml.
+package server
+
+import (
+	"crypto/rand"
+	_ "embed"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/baag_chal_gl/rules"
+)
+
+//go:embed openapi.yaml
+var openAPISpec []byte
+
+// Limits that keep any one client from growing the server without end.
+const (
+	// maxBodySize bounds a request body.
+	maxBodySize = 64 << 10
+	// maxGames bounds the games held at once.
+	maxGames = 10000
+	// IdleTimeout is how long a game is kept after its last request.
+	IdleTimeout = 24 * time.Hour
+)
+
+// game is a stored game with its players' names.
+type game struct {
+	id      string
+	created time.Time
+	// used is the time of the last request for the game
+	used  time.Time
+	goat  string
+	tiger string
+	g     *rules.Game
+}
+
+// Server holds the games in memory. Games are deleted on request or after
+// IdleTimeout without one.
+type Server struct {
+	mu    sync.Mutex
+	games map[string]*game
+	mux   *http.ServeMux
+	// now is the clock, replaced in tests
+	now func() time.Time
+}
+
+// New creates a server with no games.
+func New() *Server {
+	s := &Server{games: map[string]*game{}, mux: http.NewServeMux(), now: time.Now}
+	s.mux.HandleFunc("GET /openapi.yaml", s.handleSpec)
+	s.mux.HandleFunc("POST /games", s.handleCreate)
+	s.mux.HandleFunc("GET /games", s.handleList)
+	s.mux.HandleFunc("GET /games/{id}", s.withGame(s.handleState))
+	s.mux.HandleFunc("DELETE /games/{id}", s.withGame(s.handleDelete))
+	s.mux.HandleFunc("GET /games/{id}/moves", s.withGame(s.handleLegalMoves))
+	s.mux.HandleFunc("POST /games/{id}/moves", s.withGame(s.handleMove))
+	s.mux.HandleFunc("POST /games/{id}/undo", s.withGame(s.handleUndo))
+	s.mux.HandleFunc("GET /games/{id}/record", s.withGame(s.handleRecord))
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// State is the JSON form of a game.
+type State struct {
+	ID       string `json:"id"`
+	Position string `json:"position"`
+	// Board lists the ranks from 5 down to 1, files a to e, as 'G', 'T'
+	// or '.'.
+	Board         []string `json:"board"`
+	Turn          string   `json:"turn"`
+	PlacedGoats   int      `json:"placedGoats"`
+	CapturedGoats int      `json:"capturedGoats"`
+	GoatsInHand   int      `json:"goatsInHand"`
+	Result        string   `json:"result"`
+	Moves         []string `json:"moves"`
+	LegalMoves    []string `json:"legalMoves"`
+	Goat          string   `json:"goat,omitempty"`
+	Tiger         string   `json:"tiger,omitempty"`
+}
+
+// Error is the body of every error response.
+type Error struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// ruleErrorCodes names the reasons a move is rejected by the rules.
+var ruleErrorCodes = map[error]string{
+	rules.ErrGameOver:      "game_over",
+	rules.ErrOffBoard:      "off_board",
+	rules.ErrOccupied:      "occupied",
+	rules.ErrNotYourPiece:  "not_your_piece",
+	rules.ErrNotConnected:  "not_connected",
+	rules.ErrNoGoatToJump:  "no_goat_to_jump",
+	rules.ErrGoatsInHand:   "goats_in_hand",
+	rules.ErrNoGoatsInHand: "no_goats_in_hand",
+	rules.ErrTigerPlace:    "tiger_placement",
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	writeJSON(w, status, Error{Code: code, Message: message})
+}
+
+// readJSON decodes a request body of at most maxBodySize bytes into v. It
+// reports whether it did; if not, the error response has been written.
+func readJSON(w http.ResponseWriter, r *http.Request, v any) bool {
+	err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize)).Decode(v)
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.As(err, &tooLarge):
+		writeError(w, http.StatusRequestEntityTooLarge, "body_too_large",
+			fmt.Sprintf("request body is over %d bytes", tooLarge.Limit))
+		return false
+	case err != nil:
+		writeError(w, http.StatusBadRequest, "bad_request", "invalid JSON body: "+err.Error())
+		return false
+	}
+	return true
+}
+
+// writeRuleError reports a move rejected by the rules engine.
+func writeRuleError(w http.ResponseWriter, err error) {
+	for target, code := range ruleErrorCodes {
+		if errors.Is(err, target) {
+			status := http.StatusUnprocessableEntity
+			if target == rules.ErrGameOver {
+				status = http.StatusConflict
+			}
+			writeError(w, status, code, err.Error())
+			return
+		}
+	}
+	writeError(w, http.StatusUnprocessableEntity, "illegal_move", err.Error())
+}
+
+func newID() string {
+	var b [8]byte
+	rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+func (gm *game) state() State {
+	p := gm.g.Position()
+	st := State{
+		ID:            gm.id,
+		Position:      p.String(),
+		Turn:          "goat",
+		PlacedGoats:   p.PlacedGoats,
+		CapturedGoats: p.CapturedGoats,
+		GoatsInHand:   p.GoatsInHand(),
+		Result:        gm.g.Result().Tag(),
+		Moves:         []string{},
+		LegalMoves:    []string{},
+		Goat:          gm.goat,
+		Tiger:         gm.tiger,
+	}
+	if p.Turn == rules.Tiger {
+		st.Turn = "tiger"
+	}
+	for y := rules.Size - 1; y >= 0; y-- {
+		var row strings.Builder
+		for x := 0; x < rules.Size; x++ {
+			row.WriteByte(".GT"[p.Board[x][y]])
+		}
+		st.Board = append(st.Board, row.String())
+	}
+	for _, m := range gm.g.Moves {
+		st.Moves = append(st.Moves, m.String())
+	}
+	if gm.g.Result() == rules.Ongoing {
+		for _, m := range p.LegalMoves() {
+			st.LegalMoves = append(st.LegalMoves, m.String())
+		}
+	}
+	return st
+}
+
+func (gm *game) record() *rules.Record {
+	rec := rules.NewRecord(gm.g)
+	rec.Tags["Site"] = "HTTP game " + gm.id
+	rec.Tags["Date"] = gm.created.Format("2006.01.02")
+	if gm.goat != "" {
+		rec.Tags["Goat"] = gm.goat
+	}
+	if gm.tiger != "" {
+		rec.Tags["Tiger"] = gm.tiger
+	}
+	return rec
+}
+
+func (s *Server) handleSpec(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/yaml")
+	w.Write(openAPISpec)
+}
+
+// createRequest is the optional body of POST /games.
+type createRequest struct {
+	Position string `json:"position"`
+	Goat     string `json:"goat"`
+	Tiger    string `json:"tiger"`
+}
+
+func (s *Server) handleCreate(w http.ResponseWriter, r *http.Request) {
+	var req createRequest
+	if r.ContentLength != 0 && !readJSON(w, r, &req) {
+		return
+	}
+	start := rules.NewPosition()
+	if req.Position != "" {
+		p, err := rules.ParsePosition(req.Position)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "bad_position", err.Error())
+			return
+		}
+		start = p
+	}
+	now := s.now()
+	gm := &game{id: newID(), created: now, used: now, goat: req.Goat, tiger: req.Tiger, g: rules.NewGame(start)}
+
+	s.mu.Lock()
+	s.expire(now)
+	if len(s.games) >= maxGames {
+		s.mu.Unlock()
+		writeError(w, http.StatusServiceUnavailable, "too_many_games",
+			fmt.Sprintf("the server holds %d games; delete one or wait for idle ones to expire", maxGames))
+		return
+	}
+	s.games[gm.id] = gm
+	st := gm.state()
+	s.mu.Unlock()
+
+	w.Header().Set("Location", "/games/"+gm.id)
+	writeJSON(w, http.StatusCreated, st)
+}
+
+// expire deletes the games idle for longer than IdleTimeout. The server
+// must be locked.
+func (s *Server) expire(now time.Time) {
+	for id, gm := range s.games {
+		if now.Sub(gm.used) > IdleTimeout {
+			delete(s.games, id)
+		}
+	}
+}
+
+func (s *Server) handleList(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.expire(s.now())
+	list := make([]*game, 0, len(s.games))
+	for _, gm := range s.games {
+		list = append(list, gm)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].created.Before(list[j].created) })
+	states := make([]State, 0, len(list))
+	for _, gm := range list {
+		states = append(states, gm.state())
+	}
+	s.mu.Unlock()
+	writeJSON(w, http.StatusOK, states)
+}
+
+// withGame looks up the {id} game and runs h with the server locked.
+func (s *Server) withGame(h func(http.ResponseWriter, *http.Request, *game)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		now := s.now()
+		gm, ok := s.games[r.PathValue("id")]
+		if ok && now.Sub(gm.used) > IdleTimeout {
+			delete(s.games, gm.id)
+			ok = false
+		}
+		if !ok {
+			writeError(w, http.StatusNotFound, "not_found", fmt.Sprintf("no game %q", r.PathValue("id")))
+			return
+		}
+		gm.used = now
+		h(w, r, gm)
+	}
+}
+
+func (s *Server) handleState(w http.ResponseWriter, r *http.Request, gm *game) {
+	writeJSON(w, http.StatusOK, gm.state())
+}
+
+func (s *Server) handleDelete(w http.ResponseWriter, r *http.Request, gm *game) {
+	delete(s.games, gm.id)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleLegalMoves(w http.ResponseWriter, r *http.Request, gm *game) {
+	writeJSON(w, http.StatusOK, gm.state().LegalMoves)
+}
+
+// moveRequest is the body of POST /games/{id}/moves.
+type moveRequest struct {
+	Move string `json:"move"`
+}
+
+func (s *Server) handleMove(w http.ResponseWriter, r *http.Request, gm *game) {
+	var req moveRequest
+	if !readJSON(w, r, &req) {
+		return
+	}
+	m, err := rules.ParseMove(req.Move)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "bad_notation", err.Error())
+		return
+	}
+	if err := gm.g.Play(m); err != nil {
+		writeRuleError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, gm.state())
+}
+
+func (s *Server) handleUndo(w http.ResponseWriter, r *http.Request, gm *game) {
+	if !gm.g.Undo() {
+		writeError(w, http.StatusConflict, "nothing_to_undo", "no moves have been played")
+		return
+	}
+	writeJSON(w, http.StatusOK, gm.state())
+}
+
+func (s *Server) handleRecord(w http.ResponseWriter, r *http.Request, gm *game) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	gm.record().WriteTo(w)
+}
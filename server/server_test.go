@@ -0,0 +1,143 @@
+package server
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// call makes a request and decodes a JSON response into out, if given.
+func call(t *testing.T, ts *httptest.Server, method, path, body string, out any) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(method, ts.URL+path, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out != nil {
+		if err := json.Unmarshal(data, out); err != nil {
+			t.Fatalf("%s %s: %v in %q", method, path, err, data)
+		}
+	}
+	return resp
+}
+
+// wantError checks that a request fails with the status and error code.
+func wantError(t *testing.T, ts *httptest.Server, method, path, body string, status int, code string) {
+	t.Helper()
+	var e Error
+	resp := call(t, ts, method, path, body, &e)
+	if resp.StatusCode != status || e.Code != code {
+		t.Errorf("%s %s %s: %d %q, want %d %q", method, path, body, resp.StatusCode, e.Code, status, code)
+	}
+}
+
+func TestGameRoundTrip(t *testing.T) {
+	ts := httptest.NewServer(New())
+	defer ts.Close()
+
+	var st State
+	resp := call(t, ts, "POST", "/games", `{"goat": "alice", "tiger": "bob"}`, &st)
+	if resp.StatusCode != http.StatusCreated || resp.Header.Get("Location") != "/games/"+st.ID {
+		t.Fatalf("create: %d at %q", resp.StatusCode, resp.Header.Get("Location"))
+	}
+	if st.Turn != "goat" || st.GoatsInHand != 20 || st.Goat != "alice" || len(st.LegalMoves) != 21 {
+		t.Errorf("new game: %+v", st)
+	}
+	game := "/games/" + st.ID
+
+	resp = call(t, ts, "POST", game+"/moves", `{"move": "c3"}`, &st)
+	if resp.StatusCode != http.StatusOK || st.Turn != "tiger" || !reflect.DeepEqual(st.Moves, []string{"c3"}) {
+		t.Errorf("move c3: %d %+v", resp.StatusCode, st)
+	}
+	if st.Board[2] != "..G.." {
+		t.Errorf("board after c3: %q", st.Board)
+	}
+
+	wantError(t, ts, "POST", game+"/moves", `{"move": "b2"}`, http.StatusUnprocessableEntity, "tiger_placement")
+	wantError(t, ts, "POST", game+"/moves", `{"move": "a1-b3"}`, http.StatusUnprocessableEntity, "not_connected")
+	wantError(t, ts, "POST", game+"/moves", `{"move": "c3-c4"}`, http.StatusUnprocessableEntity, "not_your_piece")
+	wantError(t, ts, "POST", game+"/moves", `{"move": "z9"}`, http.StatusBadRequest, "bad_notation")
+	wantError(t, ts, "POST", game+"/moves", `{"move":`, http.StatusBadRequest, "bad_request")
+	big := `{"move": "` + strings.Repeat(" ", maxBodySize) + `"}`
+	wantError(t, ts, "POST", game+"/moves", big, http.StatusRequestEntityTooLarge, "body_too_large")
+
+	var legal []string
+	call(t, ts, "GET", game+"/moves", "", &legal)
+	if !reflect.DeepEqual(legal, st.LegalMoves) {
+		t.Errorf("legal moves %v, state has %v", legal, st.LegalMoves)
+	}
+
+	resp = call(t, ts, "GET", game+"/record", "", nil)
+	if resp.StatusCode != http.StatusOK || !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/plain") {
+		t.Errorf("record: %d %q", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+
+	resp = call(t, ts, "POST", game+"/undo", "", &st)
+	if resp.StatusCode != http.StatusOK || len(st.Moves) != 0 || st.Turn != "goat" {
+		t.Errorf("undo: %d %+v", resp.StatusCode, st)
+	}
+	wantError(t, ts, "POST", game+"/undo", "", http.StatusConflict, "nothing_to_undo")
+
+	var list []State
+	call(t, ts, "GET", "/games", "", &list)
+	if len(list) != 1 || list[0].ID != st.ID {
+		t.Errorf("list: %+v", list)
+	}
+
+	resp = call(t, ts, "DELETE", game, "", nil)
+	if resp.StatusCode != http.StatusNoContent {
+		t.Errorf("delete: %d", resp.StatusCode)
+	}
+	wantError(t, ts, "GET", game, "", http.StatusNotFound, "not_found")
+	wantError(t, ts, "DELETE", game, "", http.StatusNotFound, "not_found")
+}
+
+func TestCreateErrors(t *testing.T) {
+	ts := httptest.NewServer(New())
+	defer ts.Close()
+
+	wantError(t, ts, "POST", "/games", `{"position": "T3T/5/5/5"}`, http.StatusBadRequest, "bad_position")
+	wantError(t, ts, "POST", "/games", `[]`, http.StatusBadRequest, "bad_request")
+
+	var st State
+	call(t, ts, "POST", "/games", `{"position": "T3T/5/2G2/5/T3T t 1 0"}`, &st)
+	if st.Turn != "tiger" || st.PlacedGoats != 1 {
+		t.Errorf("created from a position: %+v", st)
+	}
+}
+
+func TestIdleGamesExpire(t *testing.T) {
+	srv := New()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	srv.now = func() time.Time { return now }
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	var old, used State
+	call(t, ts, "POST", "/games", "", &old)
+	call(t, ts, "POST", "/games", "", &used)
+	now = now.Add(IdleTimeout / 2)
+	call(t, ts, "GET", "/games/"+used.ID, "", nil)
+	now = now.Add(IdleTimeout/2 + time.Minute)
+
+	wantError(t, ts, "GET", "/games/"+old.ID, "", http.StatusNotFound, "not_found")
+	var list []State
+	call(t, ts, "GET", "/games", "", &list)
+	if len(list) != 1 || list[0].ID != used.ID {
+		t.Errorf("games after expiry: %+v", list)
+	}
+}
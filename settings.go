@@ -0,0 +1,238 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"io/fs"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+)
+
+// settings are the preferences kept between runs in settings.json in the
+// user config directory. Each one but the window size has a command-line
+// flag that overrides it for one run. Only what is changed in game is
+// written back, so an override is never saved by accident.
+type settings struct {
+	WindowWidth  int     `json:"windowWidth"`
+	WindowHeight int     `json:"windowHeight"`
+	Theme        string  `json:"theme"`
+	Input        string  `json:"input"`
+	Sound        bool    `json:"sound"`
+	Music        bool    `json:"music"`
+	Volume       float64 `json:"volume"`
+	Palette      string  `json:"palette"`
+	UIScale      float64 `json:"uiScale"`
+	Coords       bool    `json:"coords"`
+	Orient       string  `json:"orient"`
+	Threats      bool    `json:"threats"`
+	AI           string  `json:"ai"`
+	Level        string  `json:"level"`
+	MoveTime     string  `json:"moveTime"`
+	Clock        string  `json:"clock"`
+	// Server is the address serve last listened on.
+	Server string `json:"server"`
+}
+
+// settingsFlags pairs each setting with its flag.
+var settingsFlags = []struct {
+	name  string
+	value func(s *settings) any
+}{
+	{"theme", func(s *settings) any { return &s.Theme }},
+	{"input", func(s *settings) any { return &s.Input }},
+	{"sound", func(s *settings) any { return &s.Sound }},
+	{"music", func(s *settings) any { return &s.Music }},
+	{"volume", func(s *settings) any { return &s.Volume }},
+	{"palette", func(s *settings) any { return &s.Palette }},
+	{"uiscale", func(s *settings) any { return &s.UIScale }},
+	{"coords", func(s *settings) any { return &s.Coords }},
+	{"orient", func(s *settings) any { return &s.Orient }},
+	{"threats", func(s *settings) any { return &s.Threats }},
+	{"ai", func(s *settings) any { return &s.AI }},
+	{"level", func(s *settings) any { return &s.Level }},
+	{"movetime", func(s *settings) any { return &s.MoveTime }},
+	{"clock", func(s *settings) any { return &s.Clock }},
+	// The flag of serve; the game has no -addr
+	{"addr", func(s *settings) any { return &s.Server }},
+}
+
+var config = struct {
+	path string
+	// file is what was read, or the defaults; start is the live settings
+	// after startup, to tell what changed in game
+	file, start settings
+}{}
+
+// settingsPath is where the settings are saved.
+func settingsPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "baag_chal", "settings.json")
+}
+
+// loadSettings reads the settings file and sets every flag of flags not
+// given on the command line from it. Call it after flags.Parse.
+func loadSettings(flags *flag.FlagSet) {
+	config.path = settingsPath()
+	config.file = defaultSettings()
+	if config.path == "" {
+		return
+	}
+	data, err := os.ReadFile(config.path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return
+	case err != nil:
+		log.Printf("[Settings] %v", err)
+		return
+	}
+	if err := json.Unmarshal(data, &config.file); err != nil {
+		log.Printf("[Settings] %s: %v", config.path, err)
+		config.file = defaultSettings()
+		return
+	}
+	log.Printf("[Settings] Loaded %s", config.path)
+
+	given := map[string]bool{}
+	flags.Visit(func(f *flag.Flag) { given[f.Name] = true })
+	for _, sf := range settingsFlags {
+		if given[sf.name] || flags.Lookup(sf.name) == nil {
+			continue
+		}
+		if err := flags.Set(sf.name, settingString(sf.value(&config.file))); err != nil {
+			log.Printf("[Settings] %s: %v", sf.name, err)
+		}
+	}
+}
+
+// defaultSettings are the flag defaults, the default window size and the
+// default serve address.
+func defaultSettings() settings {
+	s := settings{WindowWidth: windowWidth, WindowHeight: windowHeight, Server: defaultServeAddr}
+	for _, sf := range settingsFlags {
+		if f := flag.Lookup(sf.name); f != nil {
+			setSetting(sf.value(&s), f.DefValue)
+		}
+	}
+	return s
+}
+
+// settingString formats a setting the way its flag is written.
+func settingString(v any) string {
+	switch v := v.(type) {
+	case *string:
+		return *v
+	case *bool:
+		return strconv.FormatBool(*v)
+	case *float64:
+		return strconv.FormatFloat(*v, 'g', -1, 64)
+	}
+	return ""
+}
+
+// setSetting parses a flag value into a setting.
+func setSetting(v any, s string) {
+	switch v := v.(type) {
+	case *string:
+		*v = s
+	case *bool:
+		*v, _ = strconv.ParseBool(s)
+	case *float64:
+		*v, _ = strconv.ParseFloat(s, 64)
+	}
+}
+
+// liveSettings reads the settings in effect now.
+func liveSettings() settings {
+	muted, musicOff := audio.mix.state()
+	orient := orientations[boardView.turns]
+	if boardView.auto {
+		orient = "auto"
+	}
+	return settings{
+		WindowWidth:  view.winW,
+		WindowHeight: view.winH,
+		Theme:        theme.ID,
+		Input:        inputStyleName(),
+		Sound:        !muted,
+		Music:        !musicOff,
+		Volume:       math.Round(audio.mix.getVolume()*100) / 100,
+		Palette:      currentPalette.ID,
+		UIScale:      uiScaleValue,
+		Coords:       boardView.coords,
+		Orient:       orient,
+		Threats:      threatOverlay,
+		AI:           aiSides[ai.side],
+		Level:        ai.level,
+		MoveTime:     ai.moveTime.String(),
+		Clock:        clock.control.String(),
+		Server:       config.file.Server,
+	}
+}
+
+// startSettings records the settings once the game has started, so that
+// saveSettings can tell what changed since.
+func startSettings() {
+	config.start = liveSettings()
+}
+
+// saveSettings writes back the settings changed in game; the rest keep
+// their value from the file.
+func saveSettings() {
+	if config.path == "" {
+		return
+	}
+	live := liveSettings()
+	if live.WindowWidth <= 0 || live.WindowHeight <= 0 {
+		// Minimized
+		live.WindowWidth, live.WindowHeight = config.start.WindowWidth, config.start.WindowHeight
+	}
+	out := config.file
+	o, s, l := reflect.ValueOf(&out).Elem(), reflect.ValueOf(config.start), reflect.ValueOf(live)
+	for i := range o.NumField() {
+		if !l.Field(i).Equal(s.Field(i)) {
+			o.Field(i).Set(l.Field(i))
+		}
+	}
+	if out == config.file {
+		return
+	}
+	if writeSettings(out) {
+		config.start = live
+	}
+}
+
+// saveServer records the address serve listens on, for the next serve.
+func saveServer(addr string) {
+	if config.path == "" || addr == config.file.Server {
+		return
+	}
+	out := config.file
+	out.Server = addr
+	writeSettings(out)
+}
+
+// writeSettings writes s to the settings file and reports whether it did.
+func writeSettings(s settings) bool {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err == nil {
+		err = os.MkdirAll(filepath.Dir(config.path), 0o755)
+	}
+	if err == nil {
+		err = os.WriteFile(config.path, append(data, '\n'), 0o644)
+	}
+	if err != nil {
+		log.Printf("[Settings] %v", err)
+		return false
+	}
+	config.file = s
+	log.Printf("[Settings] Saved %s", config.path)
+	return true
+}
@@ -0,0 +1,266 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"log"
+	"strings"
+
+	"github.com/golang/freetype/truetype"
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+// Text sizes in pixels.
+const (
+	uiTextSize    = 18
+	titleTextSize = 26
+)
+
+// textAlign is the horizontal alignment of each line relative to x.
+type textAlign int
+
+const (
+	alignLeft textAlign = iota
+	alignCenter
+	alignRight
+)
+
+// textOptions controls drawText.
+type textOptions struct {
+	size  float64
+	align textAlign
+	color [4]float32
+}
+
+var defaultText = textOptions{size: uiTextSize, color: [4]float32{1, 1, 1, 1}}
+
+// atlasCharset is rasterized up front; other runes are drawn as '?'.
+const atlasCharset = " !\"#$%&'()*+,-./0123456789:;<=>?@ABCDEFGHIJKLMNOPQRSTUVWXYZ[\\]^_`abcdefghijklmnopqrstuvwxyz{|}~" +
+	"·×÷–—‘’“”…"
+
+// glyph is one rune's cell in the atlas, in pixels relative to the pen
+// position on the baseline, plus its texture coordinates.
+type glyph struct {
+	x0, y0, x1, y1 float32
+	u0, v0, u1, v1 float32
+	advance        float32
+}
+
+// glyphAtlas holds every glyph of one font at one size in a single texture.
+type glyphAtlas struct {
+	tex        uint32
+	face       font.Face
+	glyphs     map[rune]glyph
+	ascent     float32
+	lineHeight float32
+}
+
+type atlasKey struct {
+	font *truetype.Font
+	size float64
+}
+
+// atlases caches one atlas per font and size for the life of the program.
+var atlases = map[atlasKey]*glyphAtlas{}
+
+// getAtlas returns the atlas for f at size pixels, building it on first use.
+func getAtlas(f *truetype.Font, size float64) *glyphAtlas {
+	key := atlasKey{f, size}
+	if a, ok := atlases[key]; ok {
+		return a
+	}
+	a := buildAtlas(f, size)
+	atlases[key] = a
+	return a
+}
+
+// buildAtlas rasterizes atlasCharset with a simple row packer and uploads
+// the result as an alpha texture.
+func buildAtlas(f *truetype.Font, size float64) *glyphAtlas {
+	face := truetype.NewFace(f, &truetype.Options{Size: size, DPI: 72, Hinting: font.HintingFull})
+	metrics := face.Metrics()
+	a := &glyphAtlas{
+		face:       face,
+		glyphs:     map[rune]glyph{},
+		ascent:     float32(metrics.Ascent.Ceil()),
+		lineHeight: float32(metrics.Height.Ceil()),
+	}
+
+	const pad = 2
+	atlasW := 512
+	cellH := (metrics.Ascent + metrics.Descent).Ceil() + pad
+	type placed struct {
+		r      rune
+		mask   image.Image
+		maskp  image.Point
+		bounds image.Rectangle
+		at     image.Point
+		adv    fixed.Int26_6
+	}
+	var cells []placed
+	x, y := pad, pad
+	for _, r := range atlasCharset {
+		dr, mask, maskp, adv, ok := face.Glyph(fixed.Point26_6{}, r)
+		if !ok {
+			continue
+		}
+		if x+dr.Dx()+pad > atlasW {
+			x = pad
+			y += cellH
+		}
+		cells = append(cells, placed{r, mask, maskp, dr, image.Pt(x, y), adv})
+		x += dr.Dx() + pad
+	}
+	atlasH := 1
+	for atlasH < y+cellH {
+		atlasH *= 2
+	}
+
+	rgba := image.NewRGBA(image.Rect(0, 0, atlasW, atlasH))
+	for _, c := range cells {
+		target := image.Rectangle{c.at, c.at.Add(c.bounds.Size())}
+		draw.DrawMask(rgba, target, image.NewUniform(color.White), image.Point{}, c.mask, c.maskp, draw.Over)
+		a.glyphs[c.r] = glyph{
+			x0:      float32(c.bounds.Min.X),
+			y0:      float32(c.bounds.Min.Y),
+			x1:      float32(c.bounds.Max.X),
+			y1:      float32(c.bounds.Max.Y),
+			u0:      float32(target.Min.X) / float32(atlasW),
+			v0:      float32(target.Min.Y) / float32(atlasH),
+			u1:      float32(target.Max.X) / float32(atlasW),
+			v1:      float32(target.Max.Y) / float32(atlasH),
+			advance: float32(c.adv) / 64,
+		}
+	}
+
+	a.tex = gfx.newTexture(rgba)
+
+	log.Printf("[Font] Built %dx%d glyph atlas at %.0fpx", atlasW, atlasH, size)
+	return a
+}
+
+// lookup returns the glyph for r, falling back to '?'.
+func (a *glyphAtlas) lookup(r rune) (glyph, rune) {
+	if g, ok := a.glyphs[r]; ok {
+		return g, r
+	}
+	return a.glyphs['?'], '?'
+}
+
+// lineWidth is the advance width of one line in pixels, with kerning.
+func (a *glyphAtlas) lineWidth(line string) float32 {
+	var w float32
+	prev := rune(-1)
+	for _, r := range line {
+		g, r := a.lookup(r)
+		if prev >= 0 {
+			w += float32(a.face.Kern(prev, r)) / 64
+		}
+		w += g.advance
+		prev = r
+	}
+	return w
+}
+
+// pixelsToNDC converts a size in framebuffer pixels to normalized device
+// units of the board viewport.
+func pixelsToNDC(px, py float32) (float32, float32) {
+	size := float32(view.vpSize)
+	return px * 2 / size, py * 2 / size
+}
+
+// scaledAtlas returns the atlas for a text size given in unscaled pixels.
+// Glyphs are rasterized at the display's content scale so that text keeps
+// its physical size and stays sharp on hi-DPI screens.
+func scaledAtlas(size float64) *glyphAtlas {
+	return getAtlas(mainFont, size*float64(view.contentScale*uiScale()))
+}
+
+// measureText returns the width and height of text in NDC.
+func measureText(text string, size float64) (float32, float32) {
+	if mainFont == nil {
+		return 0, 0
+	}
+	a := scaledAtlas(size)
+	lines := strings.Split(text, "\n")
+	var w float32
+	for _, line := range lines {
+		if lw := a.lineWidth(line); lw > w {
+			w = lw
+		}
+	}
+	return pixelsToNDC(w, a.lineHeight*float32(len(lines)))
+}
+
+// wrapText breaks text into lines no wider than maxW in NDC at the given
+// size. Explicit newlines are kept; a single word that is too long gets a
+// line of its own.
+func wrapText(text string, size float64, maxW float32) []string {
+	var lines []string
+	for _, para := range strings.Split(text, "\n") {
+		words := strings.Fields(para)
+		if len(words) == 0 {
+			lines = append(lines, "")
+			continue
+		}
+		line := words[0]
+		for _, w := range words[1:] {
+			if lw, _ := measureText(line+" "+w, size); lw > maxW {
+				lines = append(lines, line)
+				line = w
+				continue
+			}
+			line += " " + w
+		}
+		lines = append(lines, line)
+	}
+	if len(lines) == 1 && lines[0] == "" {
+		return nil
+	}
+	return lines
+}
+
+// drawText2D draws text in the default style with its top-left corner at
+// (x, y) in NDC.
+func drawText2D(x, y float32, text string) {
+	drawText(x, y, text, defaultText)
+}
+
+// drawText draws text with the top of its first line at y. Lines are split
+// on '\n' and each is aligned relative to x.
+func drawText(x, y float32, text string, opts textOptions) {
+	if text == "" || mainFont == nil {
+		return
+	}
+	a := scaledAtlas(opts.size)
+	sx, sy := pixelsToNDC(1, 1)
+
+	for i, line := range strings.Split(text, "\n") {
+		penX := x
+		switch opts.align {
+		case alignCenter:
+			penX -= a.lineWidth(line) * sx / 2
+		case alignRight:
+			penX -= a.lineWidth(line) * sx
+		}
+		baseline := y - (a.ascent+a.lineHeight*float32(i))*sy
+
+		prev := rune(-1)
+		for _, r := range line {
+			g, r := a.lookup(r)
+			if prev >= 0 {
+				penX += float32(a.face.Kern(prev, r)) / 64 * sx
+			}
+			prev = r
+			if g.x1 > g.x0 {
+				// Glyph y grows downwards from the baseline, NDC y upwards.
+				gfx.sprite(a.tex,
+					penX+g.x0*sx, baseline-g.y0*sy, penX+g.x1*sx, baseline-g.y1*sy,
+					g.u0, g.v0, g.u1, g.v1, opts.color)
+			}
+			penX += g.advance * sx
+		}
+	}
+}
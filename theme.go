@@ -0,0 +1,99 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"path"
+
+	"github.com/baag_chal_gl/assets"
+)
+
+// themesDir holds one directory per theme in the asset filesystem
+const themesDir = assets.ThemesDir
+
+var themeFlag = flag.String("theme", "classic", "board theme, one of the directories in assets/"+themesDir)
+
+// Theme is a theme manifest; see the assets package.
+type Theme = assets.Theme
+
+// themeColor is an RGBA colour written as "#rrggbb" or "#rrggbbaa".
+type themeColor = assets.Color
+
+// defaultTheme supplies any value a manifest leaves out.
+var defaultTheme = assets.DefaultTheme
+
+var (
+	// themes lists the installed themes sorted by ID
+	themes []*Theme
+	// theme is the active theme
+	theme = &defaultTheme
+
+	backgroundTex uint32
+	// textures caches loaded images by path so switching back is instant
+	textures = map[string]uint32{}
+)
+
+// findTheme returns the installed theme with the given ID.
+func findTheme(id string) *Theme {
+	for _, t := range themes {
+		if t.ID == id {
+			return t
+		}
+	}
+	return nil
+}
+
+// applyTheme loads a theme's art and makes it active. It can be called
+// at any time; the next frame is drawn with the new theme. Missing images
+// are drawn as plain sprites and a missing font falls back to the default.
+func applyTheme(t *Theme) error {
+	goatTex = themeTexture(t, t.Goat)
+	tigerTex = themeTexture(t, t.Tiger)
+	backgroundTex = 0
+	if t.Background != "" {
+		backgroundTex = themeTexture(t, t.Background)
+	}
+	if err := LoadFont(path.Join(t.Dir, t.Font)); err != nil {
+		log.Printf("[Theme] %s: font: %v", t.ID, err)
+		if err := LoadFont(defaultFontPath); err != nil {
+			return err
+		}
+	}
+
+	loadThemeSounds(t)
+
+	defaultText.color = t.TextColor
+	theme = t
+	log.Printf("[Theme] Using %s", t.Name)
+	return nil
+}
+
+// cycleTheme switches to the next installed theme.
+func cycleTheme() {
+	if len(themes) == 0 {
+		return
+	}
+	next := themes[0]
+	for i, t := range themes {
+		if t == theme && i+1 < len(themes) {
+			next = themes[i+1]
+		}
+	}
+	if err := applyTheme(next); err != nil {
+		log.Printf("[Theme] %s: %v", next.ID, err)
+	}
+}
+
+// themeTexture loads an image of t, or returns 0 when it is missing so the
+// caller can draw a fallback.
+func themeTexture(t *Theme, name string) uint32 {
+	return assetTexture(path.Join(t.Dir, name))
+}
+
+// drawBackground fills the board area with the theme's landscape.
+func drawBackground() {
+	if backgroundTex == 0 {
+		return
+	}
+	gfx.sprite(backgroundTex, -1, 1, 1, -1, 0, 0, 1, 1, white)
+}
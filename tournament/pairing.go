@@ -0,0 +1,174 @@
+package tournament
+
+import (
+	"fmt"
+	"sort"
+)
+
+// roundRobin schedules every pairing with the circle method. Sides
+// alternate from round to round so each player gets a fair share of both;
+// with bothSides every pairing is also played with the sides swapped.
+func roundRobin(n int, bothSides bool) []Game {
+	var ids []int
+	if n%2 == 1 {
+		// The bye takes the fixed seat so byes rotate like everyone else.
+		ids = append(ids, Bye)
+	}
+	for i := 0; i < n; i++ {
+		ids = append(ids, i)
+	}
+	m := len(ids)
+
+	var games []Game
+	for round := 1; round < m; round++ {
+		for i := 0; i < m/2; i++ {
+			a, b := ids[i], ids[m-1-i]
+			// Alternate the fixed player's side and mirror the others.
+			if (i == 0 && round%2 == 0) || (i > 0 && i%2 == 1) {
+				a, b = b, a
+			}
+			switch {
+			case a == Bye:
+				games = append(games, Game{Round: round, Goat: b, Tiger: Bye})
+			case b == Bye:
+				games = append(games, Game{Round: round, Goat: a, Tiger: Bye})
+			default:
+				games = append(games, Game{Round: round, Goat: a, Tiger: b})
+				if bothSides {
+					games = append(games, Game{Round: round, Goat: b, Tiger: a})
+				}
+			}
+		}
+		// Rotate everyone but the first player.
+		last := ids[m-1]
+		copy(ids[2:], ids[1:m-1])
+		ids[1] = last
+	}
+	return games
+}
+
+// PairNextRound pairs the next Swiss round once the previous one is
+// complete. Players are paired in order of score with the nearest opponent
+// they have not met yet, and the player who has had the tigers more often
+// gets the goats.
+func (t *Tournament) PairNextRound() ([]Game, error) {
+	if t.Format != Swiss {
+		return nil, fmt.Errorf("only Swiss tournaments are paired round by round")
+	}
+	round := t.lastRound()
+	if round >= t.Rounds {
+		return nil, fmt.Errorf("all %d rounds have been paired", t.Rounds)
+	}
+	if round > 0 && !t.RoundComplete(round) {
+		return nil, fmt.Errorf("round %d is not complete", round)
+	}
+	round++
+
+	order := t.rankedIndexes()
+	var games []Game
+	if len(order)%2 == 1 {
+		// The lowest-ranked player without a bye sits out.
+		bye := -1
+		for i := len(order) - 1; i >= 0 && bye < 0; i-- {
+			if !t.hadBye(order[i]) {
+				bye = i
+			}
+		}
+		if bye < 0 {
+			return nil, fmt.Errorf("round %d: no player left without a bye", round)
+		}
+		games = append(games, Game{Round: round, Goat: order[bye], Tiger: Bye})
+		order = append(order[:bye:bye], order[bye+1:]...)
+	}
+
+	pairs, ok := t.pairUp(order)
+	if !ok {
+		return nil, fmt.Errorf("round %d: every remaining pairing is a rematch", round)
+	}
+	for _, p := range pairs {
+		goat, tiger := t.assignSides(p[0], p[1])
+		games = append(games, Game{Round: round, Goat: goat, Tiger: tiger})
+		if t.BothSides {
+			games = append(games, Game{Round: round, Goat: tiger, Tiger: goat})
+		}
+	}
+	t.Games = append(t.Games, games...)
+	return games, nil
+}
+
+// rankedIndexes orders the players by score, then by their seed.
+func (t *Tournament) rankedIndexes() []int {
+	scores := t.Scores()
+	order := make([]int, len(t.Players))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return scores[order[a]] > scores[order[b]]
+	})
+	return order
+}
+
+// pairUp pairs players in order, backtracking when the only opponents left
+// have already been met.
+func (t *Tournament) pairUp(order []int) ([][2]int, bool) {
+	if len(order) == 0 {
+		return nil, true
+	}
+	first := order[0]
+	for j := 1; j < len(order); j++ {
+		if t.met(first, order[j]) {
+			continue
+		}
+		rest := make([]int, 0, len(order)-2)
+		rest = append(rest, order[1:j]...)
+		rest = append(rest, order[j+1:]...)
+		if pairs, ok := t.pairUp(rest); ok {
+			return append([][2]int{{first, order[j]}}, pairs...), true
+		}
+	}
+	return nil, false
+}
+
+// met reports whether players a and b have already been paired.
+func (t *Tournament) met(a, b int) bool {
+	for _, g := range t.Games {
+		if (g.Goat == a && g.Tiger == b) || (g.Goat == b && g.Tiger == a) {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *Tournament) hadBye(p int) bool {
+	for _, g := range t.Games {
+		if g.IsBye() && g.Goat == p {
+			return true
+		}
+	}
+	return false
+}
+
+// sideBalance is how many more games p has played as tiger than as goat.
+func (t *Tournament) sideBalance(p int) int {
+	n := 0
+	for _, g := range t.Games {
+		switch {
+		case g.IsBye():
+		case g.Tiger == p:
+			n++
+		case g.Goat == p:
+			n--
+		}
+	}
+	return n
+}
+
+// assignSides gives the goats to whichever of a and b is owed them; on a
+// tie the higher-ranked player a does.
+func (t *Tournament) assignSides(a, b int) (goat, tiger int) {
+	if t.sideBalance(b) > t.sideBalance(a) {
+		return b, a
+	}
+	return a, b
+}
@@ -0,0 +1,157 @@
+package tournament
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRoundRobin(t *testing.T) {
+	tests := []struct {
+		n         int
+		bothSides bool
+		rounds    int
+		games     int // byes included
+	}{
+		{2, false, 1, 1},
+		{3, false, 3, 6},
+		{4, false, 3, 6},
+		{5, false, 5, 15},
+		{6, true, 5, 30},
+		{7, true, 7, 49},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%d players both sides %v", tt.n, tt.bothSides), func(t *testing.T) {
+			games := roundRobin(tt.n, tt.bothSides)
+			if len(games) != tt.games {
+				t.Errorf("%d games, want %d", len(games), tt.games)
+			}
+			meetings := map[[2]int]int{}
+			byes := make([]int, tt.n)
+			// pairings counts each player's pairings per round
+			pairings := map[[2]int]int{}
+			for _, g := range games {
+				if g.Round < 1 || g.Round > tt.rounds {
+					t.Fatalf("game in round %d of %d", g.Round, tt.rounds)
+				}
+				if g.IsBye() {
+					byes[g.Goat]++
+					pairings[[2]int{g.Round, g.Goat}]++
+					continue
+				}
+				meetings[[2]int{g.Goat, g.Tiger}]++
+				if !tt.bothSides || g.Goat < g.Tiger {
+					pairings[[2]int{g.Round, g.Goat}]++
+					pairings[[2]int{g.Round, g.Tiger}]++
+				}
+			}
+			for a := 0; a < tt.n; a++ {
+				for b := a + 1; b < tt.n; b++ {
+					ab, ba := meetings[[2]int{a, b}], meetings[[2]int{b, a}]
+					if tt.bothSides && (ab != 1 || ba != 1) || !tt.bothSides && ab+ba != 1 {
+						t.Errorf("%d and %d meet %d times as %d-%d and %d times as %d-%d", a, b, ab, a, b, ba, b, a)
+					}
+				}
+				if want := tt.n % 2; byes[a] != want {
+					t.Errorf("player %d has %d byes, want %d", a, byes[a], want)
+				}
+				for round := 1; round <= tt.rounds; round++ {
+					if n := pairings[[2]int{round, a}]; n != 1 {
+						t.Errorf("player %d is paired %d times in round %d", a, n, round)
+					}
+				}
+			}
+		})
+	}
+}
+
+// playRound gives every unplayed game the result.
+func playRound(tr *Tournament, result string) {
+	for i := range tr.Games {
+		if !tr.Games[i].Played() {
+			tr.Games[i].Result = result
+		}
+	}
+}
+
+func TestSwissPairing(t *testing.T) {
+	tr, err := New("test", Swiss, 3, false, []Player{{Name: "a"}, {Name: "b"}, {Name: "c"}, {Name: "d"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for round := 1; round <= 3; round++ {
+		games, err := tr.PairNextRound()
+		if err != nil {
+			t.Fatalf("round %d: %v", round, err)
+		}
+		if len(games) != 2 {
+			t.Fatalf("round %d: %d games, want 2", round, len(games))
+		}
+		if _, err := tr.PairNextRound(); err == nil {
+			t.Fatalf("round %d: paired again before it was played", round)
+		}
+		playRound(tr, "goats")
+	}
+	// Four players in three rounds is a round-robin
+	for a := 0; a < 4; a++ {
+		for b := a + 1; b < 4; b++ {
+			if !tr.met(a, b) {
+				t.Errorf("%d and %d never met", a, b)
+			}
+		}
+		if n := tr.sideBalance(a); n < -1 || n > 1 {
+			t.Errorf("player %d played tiger %d more times than goat", a, n)
+		}
+	}
+	if !tr.Finished() {
+		t.Error("not finished after the last round")
+	}
+	if _, err := tr.PairNextRound(); err == nil || !strings.Contains(err.Error(), "all 3 rounds") {
+		t.Errorf("pairing after the last round: %v", err)
+	}
+}
+
+func TestPairUpBacktracks(t *testing.T) {
+	// Pairing 0 with 2 first would leave 1 and 3, who have met.
+	tr := &Tournament{
+		Format:  Swiss,
+		Players: make([]Player, 4),
+		Games:   []Game{{Goat: 0, Tiger: 1}, {Goat: 3, Tiger: 1}},
+	}
+	pairs, ok := tr.pairUp([]int{0, 1, 2, 3})
+	if want := [][2]int{{0, 3}, {1, 2}}; !ok || !reflect.DeepEqual(pairs, want) {
+		t.Errorf("pairUp = %v, %v, want %v", pairs, ok, want)
+	}
+
+	tr.Games = append(tr.Games, Game{Goat: 0, Tiger: 3}, Game{Goat: 0, Tiger: 2})
+	if pairs, ok := tr.pairUp([]int{0, 1, 2, 3}); ok {
+		t.Errorf("pairUp = %v with 0 having met everyone", pairs)
+	}
+}
+
+func TestSwissByes(t *testing.T) {
+	tr, err := New("test", Swiss, 4, false, []Player{{Name: "a"}, {Name: "b"}, {Name: "c"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	had := map[int]bool{}
+	for round := 1; round <= 3; round++ {
+		games, err := tr.PairNextRound()
+		if err != nil {
+			t.Fatalf("round %d: %v", round, err)
+		}
+		bye := games[0]
+		if !bye.IsBye() {
+			t.Fatalf("round %d: first game %+v is not a bye", round, bye)
+		}
+		if had[bye.Goat] {
+			t.Errorf("round %d: player %d has a second bye", round, bye.Goat)
+		}
+		had[bye.Goat] = true
+		playRound(tr, "draw")
+	}
+	if _, err := tr.PairNextRound(); err == nil || !strings.Contains(err.Error(), "no player left without a bye") {
+		t.Errorf("round 4: %v", err)
+	}
+}
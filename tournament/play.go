@@ -0,0 +1,47 @@
+package tournament
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/baag_chal_gl/arena"
+	"github.com/baag_chal_gl/engine"
+	"github.com/baag_chal_gl/rules"
+)
+
+// maxPlies adjudicates bot games that go on too long as draws.
+const maxPlies = 300
+
+// PlayBotGames plays every unplayed game between two bots in the current
+// rounds and records the results, calling report after each one.
+func (t *Tournament) PlayBotGames(ctx context.Context, report func(i int, g Game)) error {
+	for i, g := range t.Games {
+		if g.Played() || !t.Players[g.Goat].IsBot() || !t.Players[g.Tiger].IsBot() {
+			continue
+		}
+		goat, err := engine.Open(t.Players[g.Goat].Engine)
+		if err != nil {
+			return fmt.Errorf("%s: %v", t.Players[g.Goat].Name, err)
+		}
+		tiger, err := engine.Open(t.Players[g.Tiger].Engine)
+		if err != nil {
+			goat.Close()
+			return fmt.Errorf("%s: %v", t.Players[g.Tiger].Name, err)
+		}
+		game, result, err := arena.PlayGame(ctx, rules.NewPosition(), goat, tiger, maxPlies, 0)
+		goat.Close()
+		tiger.Close()
+		if err != nil {
+			return err
+		}
+
+		rec := rules.NewRecord(game)
+		rec.Tags["Result"] = result.Tag()
+		t.Games[i].Result = result.Tag()
+		t.Games[i].Record = rec.String()
+		if report != nil {
+			report(i, t.Games[i])
+		}
+	}
+	return nil
+}
@@ -0,0 +1,205 @@
+package tournament
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/baag_chal_gl/rules"
+)
+
+// points returns what the goat and tiger players scored in g, which is
+// not a bye.
+func (g Game) points() (goat, tiger float64) {
+	switch g.Outcome() {
+	case rules.GoatsWin:
+		return 1, 0
+	case rules.TigersWin:
+		return 0, 1
+	case rules.Draw:
+		return 0.5, 0.5
+	}
+	return 0, 0
+}
+
+// Scores returns every player's points.
+func (t *Tournament) Scores() []float64 {
+	scores := make([]float64, len(t.Players))
+	for _, g := range t.Games {
+		if !g.Played() {
+			continue
+		}
+		if g.IsBye() {
+			scores[g.Goat] += t.byePoints()
+			continue
+		}
+		goat, tiger := g.points()
+		scores[g.Goat] += goat
+		scores[g.Tiger] += tiger
+	}
+	return scores
+}
+
+// byePoints is what a bye scores: a win for each game of a pairing, so
+// two when both sides are played.
+func (t *Tournament) byePoints() float64 {
+	if t.BothSides {
+		return 2
+	}
+	return 1
+}
+
+// Standing is a player's line in the standings.
+type Standing struct {
+	Player int
+	Score  float64
+	// Wins, GoatWins and TigerWins count won games, byes excluded.
+	Wins, GoatWins, TigerWins int
+	// Buchholz is the sum of the opponents' scores.
+	Buchholz float64
+	// SonnebornBerger sums the scores of beaten opponents and half those of
+	// drawn ones.
+	SonnebornBerger float64
+}
+
+// Standings ranks the players. Ties are broken by Sonneborn-Berger then
+// wins in a round-robin, and by Buchholz then Sonneborn-Berger in a Swiss.
+func (t *Tournament) Standings() []Standing {
+	scores := t.Scores()
+	st := make([]Standing, len(t.Players))
+	for i := range st {
+		st[i] = Standing{Player: i, Score: scores[i]}
+	}
+	for _, g := range t.Games {
+		if !g.Played() || g.IsBye() {
+			continue
+		}
+		goat, tiger := g.points()
+		add := func(p, opp int, got float64, asGoat bool) {
+			s := &st[p]
+			s.Buchholz += scores[opp]
+			s.SonnebornBerger += got * scores[opp]
+			if got == 1 {
+				s.Wins++
+				if asGoat {
+					s.GoatWins++
+				} else {
+					s.TigerWins++
+				}
+			}
+		}
+		add(g.Goat, g.Tiger, goat, true)
+		add(g.Tiger, g.Goat, tiger, false)
+	}
+
+	keys := func(s Standing) []float64 {
+		if t.Format == Swiss {
+			return []float64{s.Score, s.Buchholz, s.SonnebornBerger, float64(s.Wins)}
+		}
+		return []float64{s.Score, s.SonnebornBerger, float64(s.Wins)}
+	}
+	sort.SliceStable(st, func(a, b int) bool {
+		ka, kb := keys(st[a]), keys(st[b])
+		for i := range ka {
+			if ka[i] != kb[i] {
+				return ka[i] > kb[i]
+			}
+		}
+		return false
+	})
+	return st
+}
+
+// WriteStandings prints the standings table.
+func (t *Tournament) WriteStandings(w io.Writer) {
+	tb := "SB"
+	if t.Format == Swiss {
+		tb = "Buchholz  SB"
+	}
+	fmt.Fprintf(w, "%-3s %-20s %5s  %-12s %4s %6s %6s\n", "#", "Player", "Score", tb, "Wins", "asGoat", "asTgr")
+	for i, s := range t.Standings() {
+		tbv := fmt.Sprintf("%-12.2f", s.SonnebornBerger)
+		if t.Format == Swiss {
+			tbv = fmt.Sprintf("%-8.1f %5.2f", s.Buchholz, s.SonnebornBerger)
+			tbv = fmt.Sprintf("%-12s", tbv)
+		}
+		fmt.Fprintf(w, "%-3d %-20s %5.1f  %s %4d %6d %6d\n",
+			i+1, t.Players[s.Player].Name, s.Score, tbv, s.Wins, s.GoatWins, s.TigerWins)
+	}
+}
+
+// WriteCrosstable prints each player's results against every other player
+// in standings order. A cell lists one entry per game: 1, = or 0, prefixed
+// with g or t for the side played.
+func (t *Tournament) WriteCrosstable(w io.Writer) {
+	st := t.Standings()
+	rank := make(map[int]int, len(st))
+	for i, s := range st {
+		rank[s.Player] = i
+	}
+	cells := make([][][]string, len(st))
+	for i := range cells {
+		cells[i] = make([][]string, len(st))
+	}
+	mark := func(got float64) string {
+		switch got {
+		case 1:
+			return "1"
+		case 0.5:
+			return "="
+		}
+		return "0"
+	}
+	for _, g := range t.Games {
+		if !g.Played() || g.IsBye() {
+			continue
+		}
+		goat, tiger := g.points()
+		a, b := rank[g.Goat], rank[g.Tiger]
+		cells[a][b] = append(cells[a][b], "g"+mark(goat))
+		cells[b][a] = append(cells[b][a], "t"+mark(tiger))
+	}
+
+	width := 3
+	for _, row := range cells {
+		for _, c := range row {
+			if n := len(strings.Join(c, " ")); n > width {
+				width = n
+			}
+		}
+	}
+	fmt.Fprintf(w, "%-3s %-20s", "#", "Player")
+	for i := range st {
+		fmt.Fprintf(w, " %-*d", width, i+1)
+	}
+	fmt.Fprintf(w, " %5s\n", "Score")
+	for i, s := range st {
+		fmt.Fprintf(w, "%-3d %-20s", i+1, t.Players[s.Player].Name)
+		for j := range st {
+			c := strings.Join(cells[i][j], " ")
+			if i == j {
+				c = strings.Repeat("X", width)
+			}
+			fmt.Fprintf(w, " %-*s", width, c)
+		}
+		fmt.Fprintf(w, " %5.1f\n", s.Score)
+	}
+}
+
+// ExportRecords writes the record of every played game, in game order.
+func (t *Tournament) ExportRecords(w io.Writer) error {
+	for i, g := range t.Games {
+		if !g.Played() || g.IsBye() {
+			continue
+		}
+		rec, err := t.GameRecord(i)
+		if err != nil {
+			return err
+		}
+		if _, err := rec.WriteTo(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
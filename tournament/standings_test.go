@@ -0,0 +1,75 @@
+package tournament
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestByePoints(t *testing.T) {
+	for _, bothSides := range []bool{false, true} {
+		tr := &Tournament{
+			Format:    Swiss,
+			BothSides: bothSides,
+			Players:   make([]Player, 3),
+			Games: []Game{
+				{Round: 1, Goat: 0, Tiger: Bye},
+				{Round: 1, Goat: 1, Tiger: 2, Result: "draw"},
+			},
+		}
+		if bothSides {
+			tr.Games = append(tr.Games, Game{Round: 1, Goat: 2, Tiger: 1, Result: "draw"})
+		}
+		got := tr.Scores()
+		want := []float64{1, 0.5, 0.5}
+		if bothSides {
+			want = []float64{2, 1, 1}
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("both sides %v: scores %v, want %v", bothSides, got, want)
+		}
+	}
+}
+
+func TestStandings(t *testing.T) {
+	// b and d tie on 2 points; b has the better Buchholz, d the better
+	// Sonneborn-Berger.
+	games := []Game{
+		{Round: 1, Goat: 0, Tiger: 2, Result: "goats"},
+		{Round: 1, Goat: 1, Tiger: 4, Result: "goats"},
+		{Round: 2, Goat: 2, Tiger: 3, Result: "tigers"},
+		{Round: 2, Goat: 0, Tiger: 4, Result: "tigers"},
+		{Round: 3, Goat: 2, Tiger: 4, Result: "draw"},
+		{Round: 3, Goat: 1, Tiger: 3, Result: "tigers"},
+		{Round: 4, Goat: 1, Tiger: 2, Result: "goats"},
+	}
+	players := []Player{{Name: "a"}, {Name: "b"}, {Name: "c"}, {Name: "d"}, {Name: "e"}}
+	tests := []struct {
+		format string
+		order  []int
+	}{
+		{Swiss, []int{1, 3, 4, 0, 2}},
+		{RoundRobin, []int{3, 1, 4, 0, 2}},
+	}
+	for _, tt := range tests {
+		tr := &Tournament{Format: tt.format, Rounds: 4, Players: players, Games: games}
+		var order []int
+		for _, s := range tr.Standings() {
+			order = append(order, s.Player)
+		}
+		if !reflect.DeepEqual(order, tt.order) {
+			t.Errorf("%s: order %v, want %v", tt.format, order, tt.order)
+		}
+	}
+
+	tr := &Tournament{Format: Swiss, Rounds: 4, Players: players, Games: games}
+	want := map[int]Standing{
+		1: {Player: 1, Score: 2, Wins: 2, GoatWins: 2, Buchholz: 4, SonnebornBerger: 2},
+		3: {Player: 3, Score: 2, Wins: 2, TigerWins: 2, Buchholz: 2.5, SonnebornBerger: 2.5},
+		2: {Player: 2, Score: 0.5, Buchholz: 6.5, SonnebornBerger: 0.75},
+	}
+	for _, s := range tr.Standings() {
+		if w, ok := want[s.Player]; ok && s != w {
+			t.Errorf("player %d: %+v, want %+v", s.Player, s, w)
+		}
+	}
+}
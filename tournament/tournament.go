@@ -0,0 +1,253 @@
+// Package tournament organizes round-robin and Swiss tournaments between
+// human and engine players.
+package tournament
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/baag_chal_gl/rules"
+)
+
+// Tournament formats.
+const (
+	RoundRobin = "roundrobin"
+	Swiss      = "swiss"
+)
+
+// Player is a participant. Engine is an engine spec for bots and empty for
+// humans.
+type Player struct {
+	Name   string `json:"name"`
+	Engine string `json:"engine,omitempty"`
+}
+
+// IsBot reports whether the player's games can be played automatically.
+func (p Player) IsBot() bool {
+	return p.Engine != ""
+}
+
+// Game is one scheduled game. Tiger is Bye for a player without opponent,
+// who then scores a win for each game the pairing would have had.
+type Game struct {
+	Round  int    `json:"round"`
+	Goat   int    `json:"goat"`
+	Tiger  int    `json:"tiger"`
+	Result string `json:"result"`
+	// Record is the game record text, if the moves are known.
+	Record string `json:"record,omitempty"`
+}
+
+// Bye marks the missing opponent of a bye.
+const Bye = -1
+
+// IsBye reports whether g is a bye.
+func (g Game) IsBye() bool {
+	return g.Tiger == Bye
+}
+
+// Played reports whether g has a result.
+func (g Game) Played() bool {
+	return g.IsBye() || g.Result != "" && g.Result != "*"
+}
+
+// Outcome is the game's result.
+func (g Game) Outcome() rules.Result {
+	r, _ := rules.ParseResult(g.Result)
+	return r
+}
+
+// Tournament is the whole event: players, format and games so far.
+type Tournament struct {
+	Name   string `json:"name"`
+	Format string `json:"format"`
+	// Rounds is the number of Swiss rounds; a round-robin has as many as
+	// its schedule needs.
+	Rounds int `json:"rounds"`
+	// BothSides plays every pairing twice, once with each player as goats,
+	// since the two sides are not equally strong.
+	BothSides bool     `json:"bothSides"`
+	Players   []Player `json:"players"`
+	Games     []Game   `json:"games"`
+}
+
+// New creates a tournament. A round-robin is scheduled in full straight
+// away; Swiss rounds are paired one at a time with PairNextRound.
+func New(name, format string, rounds int, bothSides bool, players []Player) (*Tournament, error) {
+	if len(players) < 2 {
+		return nil, fmt.Errorf("a tournament needs at least two players")
+	}
+	t := &Tournament{Name: name, Format: format, Rounds: rounds, BothSides: bothSides, Players: players}
+	switch format {
+	case RoundRobin:
+		t.Games = roundRobin(len(players), bothSides)
+		t.Rounds = t.lastRound()
+	case Swiss:
+		if rounds < 1 {
+			return nil, fmt.Errorf("a Swiss tournament needs at least one round")
+		}
+	default:
+		return nil, fmt.Errorf("unknown tournament format %q", format)
+	}
+	return t, nil
+}
+
+// Load reads a tournament saved with Save.
+func Load(path string) (*Tournament, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var t Tournament
+	if err := json.Unmarshal(data, &t); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	if err := t.check(); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return &t, nil
+}
+
+// check rejects a tournament that New and the methods here could not have
+// made, such as a hand-edited file naming a player that does not exist.
+func (t *Tournament) check() error {
+	if len(t.Players) < 2 {
+		return fmt.Errorf("a tournament needs at least two players")
+	}
+	switch t.Format {
+	case RoundRobin, Swiss:
+	default:
+		return fmt.Errorf("unknown tournament format %q", t.Format)
+	}
+	if t.Rounds < 1 {
+		return fmt.Errorf("bad number of rounds %d", t.Rounds)
+	}
+	player := func(p int) bool { return p >= 0 && p < len(t.Players) }
+	for i, g := range t.Games {
+		switch {
+		case g.Round < 1 || g.Round > t.Rounds:
+			return fmt.Errorf("game %d: round %d is not between 1 and %d", i+1, g.Round, t.Rounds)
+		case !player(g.Goat):
+			return fmt.Errorf("game %d: no player %d", i+1, g.Goat)
+		case !player(g.Tiger) && g.Tiger != Bye:
+			return fmt.Errorf("game %d: no player %d", i+1, g.Tiger)
+		case g.Goat == g.Tiger:
+			return fmt.Errorf("game %d: %s plays both sides", i+1, t.Players[g.Goat].Name)
+		}
+		if _, err := rules.ParseResult(g.Result); err != nil {
+			return fmt.Errorf("game %d: %v", i+1, err)
+		}
+	}
+	return nil
+}
+
+// Save writes the tournament as JSON.
+func (t *Tournament) Save(path string) error {
+	data, err := json.MarshalIndent(t, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// lastRound is the highest round with games scheduled.
+func (t *Tournament) lastRound() int {
+	n := 0
+	for _, g := range t.Games {
+		if g.Round > n {
+			n = g.Round
+		}
+	}
+	return n
+}
+
+// PlayerIndex finds a player by name.
+func (t *Tournament) PlayerIndex(name string) (int, bool) {
+	for i, p := range t.Players {
+		if p.Name == name {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// SetResult records the result of game i (an index into Games).
+func (t *Tournament) SetResult(i int, result rules.Result) error {
+	if i < 0 || i >= len(t.Games) {
+		return fmt.Errorf("no game %d", i+1)
+	}
+	if t.Games[i].IsBye() {
+		return fmt.Errorf("game %d is a bye", i+1)
+	}
+	t.Games[i].Result = result.Tag()
+	return nil
+}
+
+// ImportRecord enters the result of a finished game from its record. The
+// game is matched by its Goat and Tiger tags, and by the Round tag when the
+// same players meet more than once.
+func (t *Tournament) ImportRecord(rec *rules.Record) (int, error) {
+	goat, ok := t.PlayerIndex(rec.Tags["Goat"])
+	if !ok {
+		return 0, fmt.Errorf("record: unknown goat player %q", rec.Tags["Goat"])
+	}
+	tiger, ok := t.PlayerIndex(rec.Tags["Tiger"])
+	if !ok {
+		return 0, fmt.Errorf("record: unknown tiger player %q", rec.Tags["Tiger"])
+	}
+	result := rec.Result()
+	if result == rules.Ongoing {
+		return 0, fmt.Errorf("record: %s vs %s is not finished", rec.Tags["Goat"], rec.Tags["Tiger"])
+	}
+	round := rec.Tags["Round"]
+	for i, g := range t.Games {
+		if g.Goat != goat || g.Tiger != tiger || g.Played() {
+			continue
+		}
+		if round != "" && round != fmt.Sprint(g.Round) {
+			continue
+		}
+		t.Games[i].Result = result.Tag()
+		t.Games[i].Record = rec.String()
+		return i, nil
+	}
+	return 0, fmt.Errorf("record: no unplayed game with %s as goats and %s as tigers", rec.Tags["Goat"], rec.Tags["Tiger"])
+}
+
+// RoundComplete reports whether every game of the round has a result.
+func (t *Tournament) RoundComplete(round int) bool {
+	for _, g := range t.Games {
+		if g.Round == round && !g.Played() {
+			return false
+		}
+	}
+	return true
+}
+
+// Finished reports whether all rounds are paired and played.
+func (t *Tournament) Finished() bool {
+	return t.lastRound() == t.Rounds && t.RoundComplete(t.Rounds)
+}
+
+// GameRecord returns a record for game i: the stored one if any, otherwise
+// one holding only the tags and result.
+func (t *Tournament) GameRecord(i int) (*rules.Record, error) {
+	g := t.Games[i]
+	var rec *rules.Record
+	if g.Record != "" {
+		r, err := rules.ParseRecord(g.Record)
+		if err != nil {
+			return nil, fmt.Errorf("game %d: %v", i+1, err)
+		}
+		rec = r
+	} else {
+		rec = rules.NewRecord(rules.NewGame(rules.NewPosition()))
+	}
+	rec.Tags["Event"] = t.Name
+	rec.Tags["Round"] = fmt.Sprint(g.Round)
+	rec.Tags["Goat"] = t.Players[g.Goat].Name
+	rec.Tags["Tiger"] = t.Players[g.Tiger].Name
+	rec.Tags["Result"] = g.Outcome().Tag()
+	return rec, nil
+}
@@ -0,0 +1,59 @@
+package tournament
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSaveLoad(t *testing.T) {
+	tr, err := New("club", RoundRobin, 0, true, []Player{{Name: "a"}, {Name: "b", Engine: "hard"}, {Name: "c"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr.Games[0].Result = "goats"
+	path := filepath.Join(t.TempDir(), "club.json")
+	if err := tr.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	got, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, tr) {
+		t.Errorf("loaded %+v, saved %+v", got, tr)
+	}
+}
+
+func TestLoadRejects(t *testing.T) {
+	const players = `"players": [{"name": "a"}, {"name": "b"}]`
+	tests := []struct {
+		name, json, err string
+	}{
+		{"one player", `{"format": "swiss", "rounds": 1, "players": [{"name": "a"}]}`, "two players"},
+		{"format", `{"format": "knockout", "rounds": 1, ` + players + `}`, "unknown tournament format"},
+		{"no rounds", `{"format": "swiss", "rounds": 0, ` + players + `}`, "rounds"},
+		{"round", `{"format": "swiss", "rounds": 1, ` + players + `, "games": [{"round": 2, "goat": 0, "tiger": 1}]}`, "round 2"},
+		{"goat", `{"format": "swiss", "rounds": 1, ` + players + `, "games": [{"round": 1, "goat": 2, "tiger": 1}]}`, "no player 2"},
+		{"tiger", `{"format": "swiss", "rounds": 1, ` + players + `, "games": [{"round": 1, "goat": 0, "tiger": -2}]}`, "no player -2"},
+		{"bye goat", `{"format": "swiss", "rounds": 1, ` + players + `, "games": [{"round": 1, "goat": -1, "tiger": 1}]}`, "no player -1"},
+		{"same player", `{"format": "swiss", "rounds": 1, ` + players + `, "games": [{"round": 1, "goat": 1, "tiger": 1}]}`, "both sides"},
+		{"result", `{"format": "swiss", "rounds": 1, ` + players + `, "games": [{"round": 1, "goat": 0, "tiger": 1, "result": "1-0"}]}`, "bad result"},
+		{"json", `{"format": "swiss"`, "unexpected end"},
+	}
+	dir := t.TempDir()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, strings.ReplaceAll(tt.name, " ", "_")+".json")
+			if err := os.WriteFile(path, []byte(tt.json), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			_, err := Load(path)
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("Load: %v, want an error containing %q", err, tt.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package tui
+
+import (
+	"strings"
+
+	"github.com/baag_chal_gl/rules"
+)
+
+// ANSI attributes used by the renderer.
+const (
+	reset   = "\x1b[0m"
+	bold    = "\x1b[1m"
+	reverse = "\x1b[7m"
+	dim     = "\x1b[2m"
+	yellow  = "\x1b[33m"
+	white   = "\x1b[37m"
+	green   = "\x1b[32m"
+)
+
+// cellWidth is the number of columns between two neighbouring points.
+const cellWidth = 6
+
+// Marks picks out points on the rendered board.
+type Marks struct {
+	Cursor   [2]int
+	Selected [2]int
+	// Targets are the legal destinations of the selected piece.
+	Targets [][2]int
+}
+
+// RenderBoard draws p with box-drawing lines, rank 5 at the top. With color
+// off the pieces and marks are shown with plain characters only.
+func RenderBoard(p rules.Position, marks Marks, color bool) []string {
+	var lines []string
+	for y := rules.Size - 1; y >= 0; y-- {
+		var b strings.Builder
+		b.WriteString(string(rune('1'+y)) + "  ")
+		for x := 0; x < rules.Size; x++ {
+			pt := [2]int{x, y}
+			b.WriteString(point(p.At(pt), pt, marks, color))
+			if x < rules.Size-1 {
+				b.WriteString(strings.Repeat("─", cellWidth-1))
+			}
+		}
+		lines = append(lines, b.String())
+
+		if y == 0 {
+			break
+		}
+		// Connector row between rank y and rank y-1.
+		row := []rune(strings.Repeat(" ", 3+cellWidth*(rules.Size-1)+1))
+		for x := 0; x < rules.Size; x++ {
+			col := 3 + cellWidth*x
+			row[col] = '│'
+			if x == rules.Size-1 {
+				continue
+			}
+			mid := col + cellWidth/2
+			switch {
+			case rules.Connected([2]int{x, y}, [2]int{x + 1, y - 1}):
+				row[mid] = '╲'
+			case rules.Connected([2]int{x + 1, y}, [2]int{x, y - 1}):
+				row[mid] = '╱'
+			}
+		}
+		lines = append(lines, string(row))
+	}
+
+	var files strings.Builder
+	files.WriteString("   ")
+	for x := 0; x < rules.Size; x++ {
+		files.WriteString(string(rune('a' + x)))
+		if x < rules.Size-1 {
+			files.WriteString(strings.Repeat(" ", cellWidth-1))
+		}
+	}
+	lines = append(lines, files.String())
+	return lines
+}
+
+// point renders one board point.
+func point(piece int, pt [2]int, marks Marks, color bool) string {
+	s := "·"
+	switch piece {
+	case rules.Goat:
+		s = "G"
+	case rules.Tiger:
+		s = "T"
+	}
+	isTarget := false
+	for _, t := range marks.Targets {
+		if t == pt {
+			isTarget = true
+		}
+	}
+
+	if !color {
+		switch {
+		case pt == marks.Cursor:
+			if piece == rules.Empty {
+				return "@"
+			}
+			return strings.ToLower(s)
+		case isTarget:
+			return "*"
+		}
+		return s
+	}
+
+	style := dim
+	switch piece {
+	case rules.Goat:
+		style = bold + white
+	case rules.Tiger:
+		style = bold + yellow
+	}
+	if isTarget {
+		style = bold + green
+		if piece == rules.Empty {
+			s = "○"
+		}
+	}
+	if pt == marks.Selected {
+		style += "\x1b[4m"
+	}
+	if pt == marks.Cursor {
+		style += reverse
+	}
+	return style + s + reset
+}
@@ -0,0 +1,79 @@
+package tui
+
+import (
+	"bufio"
+	"io"
+)
+
+type keyKind int
+
+const (
+	keyRune keyKind = iota
+	keyUp
+	keyDown
+	keyLeft
+	keyRight
+	keyEnter
+	keyBackspace
+	keyEscape
+	keyQuit
+)
+
+type key struct {
+	kind keyKind
+	r    rune
+}
+
+// readKeys decodes raw terminal input into keys until in is closed.
+func readKeys(in io.Reader, keys chan<- key) {
+	defer close(keys)
+	r := bufio.NewReader(in)
+	for {
+		c, _, err := r.ReadRune()
+		if err != nil {
+			return
+		}
+		switch c {
+		case 3, 4: // Ctrl-C, Ctrl-D
+			keys <- key{kind: keyQuit}
+		case '\r', '\n':
+			keys <- key{kind: keyEnter}
+		case 127, 8:
+			keys <- key{kind: keyBackspace}
+		case 27:
+			keys <- escapeSequence(r)
+		default:
+			if c >= ' ' {
+				keys <- key{kind: keyRune, r: c}
+			}
+		}
+	}
+}
+
+// escapeSequence decodes the rest of an ESC sequence. A lone ESC is only
+// recognised when nothing else is already buffered.
+func escapeSequence(r *bufio.Reader) key {
+	if r.Buffered() == 0 {
+		return key{kind: keyEscape}
+	}
+	b, _ := r.ReadByte()
+	if b != '[' && b != 'O' {
+		return key{kind: keyEscape}
+	}
+	c, _ := r.ReadByte()
+	switch c {
+	case 'A':
+		return key{kind: keyUp}
+	case 'B':
+		return key{kind: keyDown}
+	case 'C':
+		return key{kind: keyRight}
+	case 'D':
+		return key{kind: keyLeft}
+	}
+	// Skip the parameters of sequences we do not use, such as F-keys.
+	for c >= '0' && c <= '9' || c == ';' {
+		c, _ = r.ReadByte()
+	}
+	return key{kind: keyEscape}
+}
@@ -0,0 +1,285 @@
+// Package tui is a terminal front-end for playing over SSH or anywhere a
+// window cannot be opened. It draws the board with Unicode line characters
+// and plays through the same rules package as the OpenGL client.
+package tui
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/baag_chal_gl/engine"
+	"github.com/baag_chal_gl/rules"
+	"golang.org/x/term"
+)
+
+// Options configure a session.
+type Options struct {
+	// Start is the starting position.
+	Start rules.Position
+	// Engine, if set, plays the side given by EngineSide.
+	Engine     engine.Engine
+	EngineSide int
+	// Color enables ANSI colours and highlighting.
+	Color bool
+}
+
+// session is the state of one terminal game.
+type session struct {
+	opts     Options
+	game     *rules.Game
+	cursor   [2]int
+	selected [2]int
+	input    string
+	message  string
+	quit     bool
+}
+
+// Run plays a game on the terminal. When in is a terminal it is switched to
+// raw mode for cursor keys; otherwise moves are read one per line.
+func Run(in *os.File, out io.Writer, opts Options) error {
+	s := &session{
+		opts:     opts,
+		game:     rules.NewGame(opts.Start),
+		cursor:   [2]int{2, 2},
+		selected: rules.NoPoint,
+	}
+	if !term.IsTerminal(int(in.Fd())) {
+		return s.runLines(in, out)
+	}
+
+	state, err := term.MakeRaw(int(in.Fd()))
+	if err != nil {
+		return err
+	}
+	defer term.Restore(int(in.Fd()), state)
+	fmt.Fprint(out, "\x1b[?25l")
+	defer fmt.Fprint(out, "\x1b[?25h\r\n")
+
+	keys := make(chan key)
+	go readKeys(in, keys)
+
+	s.message = "Arrows move the cursor, Enter selects. Type moves like c3 or a1-b2, or: undo, new, quit."
+	for !s.quit {
+		s.engineMove()
+		s.draw(out)
+		k, ok := <-keys
+		if !ok {
+			return nil
+		}
+		s.handleKey(k)
+	}
+	return nil
+}
+
+// runLines is the non-interactive mode: one move or command per line.
+func (s *session) runLines(in io.Reader, out io.Writer) error {
+	s.opts.Color = false
+	sc := bufio.NewScanner(in)
+	for !s.quit {
+		s.engineMove()
+		s.printPlain(out)
+		if s.game.Result() != rules.Ongoing {
+			return nil
+		}
+		fmt.Fprint(out, "> ")
+		if !sc.Scan() {
+			return sc.Err()
+		}
+		s.message = ""
+		s.command(sc.Text())
+	}
+	return nil
+}
+
+// engineMove lets the engine move while it is its turn.
+func (s *session) engineMove() {
+	for s.opts.Engine != nil && s.game.Result() == rules.Ongoing &&
+		s.game.Position().Turn == s.opts.EngineSide {
+		m, err := s.opts.Engine.BestMove(context.Background(), s.game)
+		if err != nil {
+			s.message = "Engine error: " + err.Error()
+			return
+		}
+		if err := s.game.Play(m); err != nil {
+			s.message = fmt.Sprintf("Engine played illegal move %s: %v", m, err)
+			return
+		}
+		s.message = fmt.Sprintf("%s played %s", s.opts.Engine.Name(), m)
+	}
+}
+
+// play submits a move through the rules package.
+func (s *session) play(m rules.Move) {
+	if err := s.game.Play(m); err != nil {
+		s.message = fmt.Sprintf("Illegal move %s: %v", m, err)
+		return
+	}
+	s.message = "Played " + m.String()
+	s.selected = rules.NoPoint
+}
+
+// command handles a typed line: a move in notation or a command word.
+func (s *session) command(line string) {
+	line = strings.TrimSpace(line)
+	switch strings.ToLower(line) {
+	case "":
+	case "quit", "exit":
+		s.quit = true
+	case "new", "reset":
+		s.game = rules.NewGame(s.opts.Start)
+		s.selected = rules.NoPoint
+		s.message = "New game."
+	case "undo":
+		// Take back the engine's reply too, so it is the player's turn
+		// again, as the game window does.
+		if !s.game.Undo() {
+			s.message = "No move to take back."
+			return
+		}
+		if s.opts.Engine != nil && s.game.Position().Turn == s.opts.EngineSide && s.game.Ply() > 0 {
+			s.game.Undo()
+		}
+		s.selected = rules.NoPoint
+		s.message = "Move taken back."
+	case "moves":
+		p := s.game.Position()
+		var names []string
+		for _, m := range p.LegalMoves() {
+			names = append(names, m.String())
+		}
+		s.message = "Legal: " + strings.Join(names, " ")
+	default:
+		m, err := rules.ParseMove(line)
+		if err != nil {
+			s.message = fmt.Sprintf("Unknown move or command %q", line)
+			return
+		}
+		s.play(m)
+	}
+}
+
+// activate is Enter on the cursor point with no typed text: place a goat,
+// pick up a piece, or move the picked-up piece here.
+func (s *session) activate() {
+	p := s.game.Position()
+	if s.selected != rules.NoPoint {
+		if s.cursor == s.selected {
+			s.selected = rules.NoPoint
+			return
+		}
+		s.play(rules.Move{From: s.selected, To: s.cursor})
+		return
+	}
+	switch {
+	case p.Turn == rules.Goat && p.PlacedGoats < rules.MaxGoats:
+		s.play(rules.Place(s.cursor))
+	case p.At(s.cursor) == p.Turn:
+		s.selected = s.cursor
+		s.message = "Selected " + rules.PointString(s.cursor)
+	default:
+		s.message = "Nothing of yours to select at " + rules.PointString(s.cursor)
+	}
+}
+
+func (s *session) handleKey(k key) {
+	switch k.kind {
+	case keyUp:
+		s.moveCursor(0, 1)
+	case keyDown:
+		s.moveCursor(0, -1)
+	case keyLeft:
+		s.moveCursor(-1, 0)
+	case keyRight:
+		s.moveCursor(1, 0)
+	case keyEnter:
+		if s.input != "" {
+			s.command(s.input)
+			s.input = ""
+		} else if s.game.Result() == rules.Ongoing {
+			s.activate()
+		}
+	case keyBackspace:
+		if s.input != "" {
+			s.input = s.input[:len(s.input)-1]
+		}
+	case keyEscape:
+		s.input = ""
+		s.selected = rules.NoPoint
+	case keyQuit:
+		s.quit = true
+	case keyRune:
+		if k.r == ' ' && s.input == "" {
+			s.activate()
+			return
+		}
+		s.input += string(k.r)
+	}
+}
+
+func (s *session) moveCursor(dx, dy int) {
+	next := [2]int{s.cursor[0] + dx, s.cursor[1] + dy}
+	if rules.OnBoard(next) {
+		s.cursor = next
+	}
+}
+
+// status is the banner shown above the board: the same goat counts as the
+// OpenGL client plus whose turn it is.
+func (s *session) status() []string {
+	p := s.game.Position()
+	turn := "Goats to move"
+	if p.Turn == rules.Tiger {
+		turn = "Tigers to move"
+	}
+	if r := s.game.Result(); r != rules.Ongoing {
+		turn = "Game over: " + r.String() + ". Type new or quit."
+	}
+	return []string{p.Summary(), turn}
+}
+
+func (s *session) marks() Marks {
+	m := Marks{Cursor: s.cursor, Selected: s.selected}
+	if s.selected != rules.NoPoint {
+		p := s.game.Position()
+		for _, mv := range p.LegalMoves() {
+			if mv.From == s.selected {
+				m.Targets = append(m.Targets, mv.To)
+			}
+		}
+	}
+	return m
+}
+
+// draw repaints the whole screen. Raw mode needs explicit carriage returns.
+func (s *session) draw(out io.Writer) {
+	var b strings.Builder
+	b.WriteString("\x1b[H\x1b[2J")
+	for _, line := range s.status() {
+		b.WriteString(line + "\r\n")
+	}
+	b.WriteString("\r\n")
+	for _, line := range RenderBoard(s.game.Position(), s.marks(), s.opts.Color) {
+		b.WriteString("  " + line + "\r\n")
+	}
+	b.WriteString("\r\n" + s.message + "\r\n")
+	b.WriteString("> " + s.input)
+	io.WriteString(out, b.String())
+}
+
+// printPlain prints the board for line mode.
+func (s *session) printPlain(out io.Writer) {
+	for _, line := range s.status() {
+		fmt.Fprintln(out, line)
+	}
+	marks := Marks{Cursor: rules.NoPoint, Selected: rules.NoPoint}
+	for _, line := range RenderBoard(s.game.Position(), marks, false) {
+		fmt.Fprintln(out, "  "+line)
+	}
+	if s.message != "" {
+		fmt.Fprintln(out, s.message)
+	}
+}